@@ -0,0 +1,122 @@
+package gonfig
+
+import (
+	"net"
+	"reflect"
+	"strings"
+)
+
+// SecretTag defines the struct tag key used to mark a field as sensitive.
+// Values of fields tagged with `secret:"true"` (or of nested fields of a tagged struct) are never
+// exposed as is, they are replaced with RedactedValue in the usage of flags and environment variables,
+// in errors of parsers, in the Change reported by Diff, in the FieldSource reported by Loader.LoadExplain
+// and in the printed configuration (see WithConfigCommands). Fields of the Secret type are secret as well.
+//
+// Example usage: `secret:"true"`
+const SecretTag = "secret"
+
+// ReloadTag defines the struct tag key used to mark a field as immutable at runtime.
+// Fields tagged with `reload:"false"` (e.g. a listen address or a data directory) can not be
+// changed without a restart, so a reload that produces a different value for them is rejected.
+//
+// Example usage: `reload:"false"`
+const ReloadTag = "reload"
+
+// RedactedValue is the placeholder used instead of the values of secret fields.
+const RedactedValue = "******"
+
+// Change describes a single field that differs between two configurations.
+//
+// Fields:
+// - Path: The full path to the field in the nested structure (e.g. "Database.MaxConns").
+// - Old: The previous value of the field, or RedactedValue if the field is secret.
+// - New: The new value of the field, or RedactedValue if the field is secret.
+// - Source: The type of the parser responsible for the new value, if it is known.
+// - RequiresRestart: Reports whether the field is tagged with `reload:"false"`.
+type Change struct {
+	Path   string
+	Old    any
+	New    any
+	Source ParserType
+
+	RequiresRestart bool
+}
+
+// Diff compares two configurations field by field and returns the list of changed fields
+// in the order of their declaration. Both arguments must be pointers to structs of the same
+// type, a nil pointer is treated as a zero value of that type. If the arguments can not be
+// compared, Diff returns nil.
+//
+// Nested structs are compared recursively, while values of fields tagged with `secret:"true"`
+// are replaced with RedactedValue in the returned changes. Changes of fields tagged with
+// `reload:"false"` are marked with RequiresRestart.
+//
+// Example usage:
+//
+//	for _, change := range gonfig.Diff(oldConfig, newConfig) {
+//	    log.Printf("%s changed from %v to %v", change.Path, change.Old, change.New)
+//	}
+func Diff(old, new any) []Change {
+	prev, next := reflect.ValueOf(old), reflect.ValueOf(new)
+	if prev.Kind() != reflect.Ptr || next.Kind() != reflect.Ptr || prev.Type() != next.Type() {
+		return nil
+	}
+
+	if prev.IsNil() {
+		prev = reflect.New(prev.Type().Elem())
+	}
+
+	if next.IsNil() {
+		next = reflect.New(next.Type().Elem())
+	}
+
+	options := ReflectOptions{CanInterface: True(), AsField: []reflect.Type{reflect.TypeOf(net.IPNet{})}}
+
+	values := make(map[string]any)
+	for elem, err := range ReflectFieldsOf(prev.Interface(), options) {
+		if err != nil {
+			return nil
+		}
+
+		values[elem.Path()] = elem.Value.Interface()
+	}
+
+	var changes []Change
+	for elem, err := range ReflectFieldsOf(next.Interface(), options) {
+		if err != nil {
+			return nil
+		}
+
+		path := elem.Path()
+		if reflect.DeepEqual(values[path], elem.Value.Interface()) {
+			continue
+		}
+
+		change := Change{Path: path, Old: values[path], New: elem.Value.Interface()}
+		change.RequiresRestart = hasOwnerTag(elem, ReloadTag, "false")
+		if isSecret(elem) {
+			change.Old, change.New = RedactedValue, RedactedValue
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// Matches reports whether the change relates to the provided path, either to the field itself
+// or to one of the fields nested into it (e.g. "Database" matches "Database.MaxConns").
+func (c Change) Matches(path string) bool {
+	return c.Path == path || strings.HasPrefix(c.Path, path+".")
+}
+
+// hasOwnerTag reports whether the field or any of its owners has the tag with the provided value.
+func hasOwnerTag(elem *ReflectValue, tag, value string) bool {
+	for owner := elem; owner != nil; owner = owner.Owner {
+		if owner.Field.Tag.Get(tag) == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,100 @@
+package gonfig_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/im-kulikov/gonfig"
+)
+
+type DiffConfig struct {
+	Address string
+	Timeout time.Duration
+	Network net.IPNet
+
+	DB struct {
+		MaxConns int
+		Password string `secret:"true"`
+	}
+
+	Credentials struct {
+		Token string
+	} `secret:"true"`
+
+	hidden int
+}
+
+func TestDiff(t *testing.T) {
+	var prev, next DiffConfig
+	prev.Address = ":8080"
+	prev.DB.MaxConns = 10
+	prev.DB.Password = "old-password"
+	prev.hidden = 1
+
+	next = prev
+	next.Timeout = time.Second
+	next.Network = net.IPNet{IP: net.ParseIP("10.0.0.0"), Mask: net.CIDRMask(8, 32)}
+	next.DB.MaxConns = 20
+	next.DB.Password = "new-password"
+	next.Credentials.Token = "token"
+	next.hidden = 2
+
+	require.Equal(t, []gonfig.Change{
+		{Path: "Timeout", Old: time.Duration(0), New: time.Second},
+		{Path: "Network", Old: net.IPNet{}, New: next.Network},
+		{Path: "DB.MaxConns", Old: 10, New: 20},
+		{Path: "DB.Password", Old: gonfig.RedactedValue, New: gonfig.RedactedValue},
+		{Path: "Credentials.Token", Old: gonfig.RedactedValue, New: gonfig.RedactedValue},
+	}, gonfig.Diff(&prev, &next))
+
+	require.Empty(t, gonfig.Diff(&prev, &prev))
+
+	t.Run("nil pointer", func(t *testing.T) {
+		require.Equal(t, []gonfig.Change{
+			{Path: "Address", Old: "", New: ":8080"},
+			{Path: "DB.MaxConns", Old: 0, New: 10},
+			{Path: "DB.Password", Old: gonfig.RedactedValue, New: gonfig.RedactedValue},
+		}, gonfig.Diff((*DiffConfig)(nil), &prev))
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		require.Nil(t, gonfig.Diff(prev, next))
+		require.Nil(t, gonfig.Diff(&prev, &struct{}{}))
+		require.Nil(t, gonfig.Diff(new(int), new(int)))
+	})
+}
+
+func TestChange_Matches(t *testing.T) {
+	change := gonfig.Change{Path: "DB.MaxConns"}
+
+	require.True(t, change.Matches("DB.MaxConns"))
+	require.True(t, change.Matches("DB"))
+	require.False(t, change.Matches("DB.Max"))
+	require.False(t, change.Matches("Address"))
+}
+
+func TestValue_SubscribePath(t *testing.T) {
+	value := gonfig.NewValue[DiffConfig](nil)
+
+	var changes []gonfig.Change
+	cancel := value.SubscribePath("DB", func(change gonfig.Change) {
+		changes = append(changes, change)
+	})
+
+	require.NotPanics(t, value.SubscribePath("DB", nil))
+
+	value.Store(&DiffConfig{Address: ":8080"})
+	require.Empty(t, changes)
+
+	next := &DiffConfig{Address: ":8081"}
+	next.DB.MaxConns = 5
+	value.Store(next)
+	require.Equal(t, []gonfig.Change{{Path: "DB.MaxConns", Old: 0, New: 5}}, changes)
+
+	cancel()
+	value.Store(&DiffConfig{})
+	require.Len(t, changes, 1)
+}
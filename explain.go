@@ -0,0 +1,147 @@
+package gonfig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// FieldSource describes where the final value of a field came from.
+//
+// Fields:
+//   - Path: The full path to the field in the nested structure (e.g. "Database.MaxConns").
+//   - Source: The type of the parser that set the final value.
+//   - Key: The key of the value in the source: the name of the environment variable (e.g. "APP_DB_HOST"),
+//     the flag (e.g. "--db-host"), the key of a MapSource (e.g. "db.host", or its location like
+//     "config.yaml:12", see SourceLocator). It is empty for custom parsers that decode values directly.
+//   - Raw: The raw input, e.g. the string of the environment variable or the flag, or RedactedValue
+//     if the field is secret. It is empty for custom parsers that decode values directly.
+type FieldSource struct {
+	Path   string
+	Source ParserType
+	Key    string
+	Raw    string
+}
+
+// String returns the human-readable description of the source, e.g.
+// `Database.MaxConns = "10" (env APP_DB_MAX_CONNS)`.
+func (s FieldSource) String() string {
+	out := s.Path
+	if s.Raw != "" {
+		out += " = " + strconv.Quote(s.Raw)
+	}
+
+	return fmt.Sprintf("%s (%s)", out, s.origin())
+}
+
+// origin returns the type of the parser with the key of the value, e.g. "env APP_DB_MAX_CONNS".
+func (s FieldSource) origin() string {
+	if s.Key != "" {
+		return fmt.Sprintf("%s %s", s.Source, s.Key)
+	}
+
+	return string(s.Source)
+}
+
+// SourceLocator is an optional interface for a MapSource that knows locations of its keys,
+// e.g. a file source can report "config.yaml:12" for the key "db.host". The location is used
+// as the Key of the FieldSource instead of the key itself.
+type SourceLocator interface {
+	// Locate returns the location of the key (nested keys are joined by dots), or an empty
+	// string if it is unknown.
+	Locate(key string) string
+}
+
+// Loader is a ContextParser returned by New and NewE. Besides loading, it can report which parser
+// set every field of the destination, so wrong values can be traced back to their sources.
+type Loader interface {
+	ContextParser
+
+	// LoadExplain loads the configuration into the destination like LoadContext and returns sources
+	// of the fields set by the load, in the order of their declaration. Fields that were not set by any
+	// parser are omitted. Sources are returned even if validation of required fields fails, the loader
+	// itself does not keep them (nor the destination).
+	//
+	// Example usage:
+	//
+	//	sources, err := loader.LoadExplain(ctx, &cfg)
+	//	for _, source := range sources {
+	//	    log.Println(source)
+	//	}
+	LoadExplain(ctx context.Context, dest any) ([]FieldSource, error)
+}
+
+// loaderParser is the Loader returned by New and NewE. The loader is nil if it could not be built,
+// then every load returns the error of the build and nothing is explained.
+type loaderParser struct {
+	contextParserFunc
+
+	svc *loader
+}
+
+// LoadExplain loads the configuration into the destination and returns sources of the fields set by the load.
+func (p *loaderParser) LoadExplain(ctx context.Context, dest any) ([]FieldSource, error) {
+	if p.svc == nil {
+		return nil, p.LoadContext(ctx, dest)
+	}
+
+	var sources []FieldSource
+	err := wrapUsageLoader(p.svc, func(ctx context.Context, v any) (err error) {
+		sources, err = p.svc.explain(ctx, v)
+
+		return err
+	})(ctx, dest)
+
+	return sources, err
+}
+
+// explain returns sources of fields recorded during the load, in the order of their declaration.
+func (s *layers) explain() []FieldSource {
+	var out []FieldSource
+	for elem, err := range ReflectFieldsOf(s.dest, treeOptions) {
+		if err != nil {
+			return nil
+		}
+
+		if source, ok := s.sources[elem.Path()]; ok {
+			out = append(out, source)
+		}
+	}
+
+	return out
+}
+
+// record stores sources of fields that were changed by the parser which decoded values into the
+// destination directly, comparing the destination with its copy taken before the parser.
+func (s *layers) record(typ ParserType, before any) {
+	for _, change := range Diff(before, s.dest) {
+		s.sources[change.Path] = FieldSource{Path: change.Path, Source: typ}
+	}
+}
+
+// snapshot returns a shallow copy of the destination, or nil if it is not a pointer to a struct.
+func snapshot(dest any) any {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	out := reflect.New(rv.Type().Elem())
+	out.Elem().Set(rv.Elem())
+
+	return out.Interface()
+}
+
+// rawString formats the raw input of the field for FieldSource, values of secret fields are redacted.
+func rawString(field *ReflectValue, value any) string {
+	if isSecret(field) {
+		return RedactedValue
+	}
+
+	if str, ok := value.(string); ok {
+		return str
+	}
+
+	return fmt.Sprint(value)
+}
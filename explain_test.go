@@ -0,0 +1,132 @@
+package gonfig_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/im-kulikov/gonfig"
+)
+
+type ExplainConfig struct {
+	Address  string `json:"address" env:"ADDRESS" flag:"address" default:":8080"`
+	Level    string `json:"level" env:"LEVEL" default:"info"`
+	Password string `json:"password" env:"PASSWORD" secret:"true"`
+	Name     string `json:"name"`
+	Custom   string
+	Unset    string
+
+	Database struct {
+		Host     string `json:"host" env:"HOST"`
+		MaxConns int    `json:"max_conns" env:"MAX_CONNS" default:"10"`
+	} `json:"db" env:"DB"`
+}
+
+type locatedSource struct {
+	mapSource
+}
+
+func (s *locatedSource) Locate(key string) string {
+	if key == "db.max_conns" {
+		return "config.yaml:12"
+	}
+
+	return ""
+}
+
+func TestLoader_Explain(t *testing.T) {
+	file := &locatedSource{mapSource: mapSource{name: "file", values: map[string]any{
+		"name": "app",
+		"db":   map[string]any{"max_conns": 20},
+	}}}
+
+	loader := gonfig.New(gonfig.Config{
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_LEVEL=debug", "APP_PASSWORD=secret", "APP_DB_HOST=db"},
+		Args:      []string{"--address", ":9090"},
+	},
+		gonfig.WithMapSource(file),
+		gonfig.WithCustomParser(gonfig.NewCustomParser("custom", func(dest any) error {
+			dest.(*ExplainConfig).Custom = "custom"
+
+			return nil
+		})))
+
+	var cfg ExplainConfig
+	sources, err := loader.LoadExplain(context.Background(), &cfg)
+	require.NoError(t, err)
+
+	require.Equal(t, []gonfig.FieldSource{
+		{Path: "Address", Source: gonfig.ParserFlags, Key: "--address", Raw: ":9090"},
+		{Path: "Level", Source: gonfig.ParserEnv, Key: "APP_LEVEL", Raw: "debug"},
+		{Path: "Password", Source: gonfig.ParserEnv, Key: "APP_PASSWORD", Raw: gonfig.RedactedValue},
+		{Path: "Name", Source: "file", Key: "name", Raw: "app"},
+		{Path: "Custom", Source: "custom"},
+		{Path: "Database.Host", Source: gonfig.ParserEnv, Key: "APP_DB_HOST", Raw: "db"},
+		{Path: "Database.MaxConns", Source: "file", Key: "config.yaml:12", Raw: "20"},
+	}, sources)
+
+	require.NoError(t, loader.Load(&cfg), "sources are not required")
+
+	t.Run("defaults", func(t *testing.T) {
+		loader := gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}})
+
+		sources, err := loader.LoadExplain(context.Background(), &ExplainConfig{})
+		require.NoError(t, err)
+		require.Equal(t, []gonfig.FieldSource{
+			{Path: "Address", Source: gonfig.ParserDefaults, Raw: ":8080"},
+			{Path: "Level", Source: gonfig.ParserDefaults, Raw: "info"},
+			{Path: "Database.MaxConns", Source: gonfig.ParserDefaults, Raw: "10"},
+		}, sources)
+	})
+
+	t.Run("parallel", func(t *testing.T) {
+		remote := &mapSource{name: "remote", values: map[string]any{"name": "remote"}}
+		loader := gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+			gonfig.WithMapSource(remote),
+			gonfig.WithParallelParsers("remote"))
+
+		sources, err := loader.LoadExplain(context.Background(), &ExplainConfig{})
+		require.NoError(t, err)
+		require.Contains(t, sources, gonfig.FieldSource{Path: "Name", Source: "remote"})
+	})
+
+	t.Run("string", func(t *testing.T) {
+		require.Equal(t, `Level = "debug" (env APP_LEVEL)`,
+			gonfig.FieldSource{Path: "Level", Source: gonfig.ParserEnv, Key: "APP_LEVEL", Raw: "debug"}.String())
+		require.Equal(t, `Custom (custom)`, gonfig.FieldSource{Path: "Custom", Source: "custom"}.String())
+	})
+
+	t.Run("broken loader", func(t *testing.T) {
+		loader := gonfig.New(gonfig.Config{}, gonfig.WithOrder("unknown"))
+		sources, err := loader.LoadExplain(context.Background(), &ExplainConfig{})
+		require.Error(t, err)
+		require.Nil(t, sources)
+	})
+}
+
+func TestValue_ChangeSource(t *testing.T) {
+	file := &mapSource{name: "file", values: map[string]any{"level": "warn"}}
+	value := gonfig.NewValue[ExplainConfig](gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+		gonfig.WithMapSource(file)))
+	require.NoError(t, value.Reload())
+
+	var changes []gonfig.Change
+	cancel := value.SubscribePath("Level", func(change gonfig.Change) { changes = append(changes, change) })
+	defer cancel()
+
+	file.values = map[string]any{"level": "debug"}
+	require.NoError(t, value.Reload())
+
+	file.values = map[string]any{}
+	require.NoError(t, value.Reload())
+
+	value.Store(&ExplainConfig{Level: "error"})
+
+	require.Equal(t, []gonfig.Change{
+		{Path: "Level", Old: "warn", New: "debug", Source: "file"},
+		{Path: "Level", Old: "debug", New: "info", Source: gonfig.ParserDefaults},
+		{Path: "Level", Old: "info", New: "error"},
+	}, changes)
+}
@@ -0,0 +1,802 @@
+package gonfig
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of the exported configuration, see Export.
+const (
+	FormatJSON = "json" // FormatJSON renders the configuration as a JSON object.
+	FormatYAML = "yaml" // FormatYAML renders the configuration as a YAML document.
+	FormatTOML = "toml" // FormatTOML renders the configuration as a TOML document.
+	FormatEnv  = "env"  // FormatEnv renders the configuration as environment variables, one per line.
+)
+
+// ExportOption defines a function type used to configure options of Export.
+type ExportOption func(*exportOptions)
+
+// exportOptions holds options of the exported configuration.
+type exportOptions struct {
+	prefix       string        // prefix of environment variables, see Config.EnvPrefix
+	sources      []FieldSource // sources of values, rendered as comments, see Loader.LoadExplain
+	omitDefaults bool          // values equal to the "default" tag are omitted
+	secrets      bool          // values of secret fields are exported as is
+	autoEnv      bool          // names of untagged fields are derived like Config.AutoEnv does
+	envDelimiter string        // delimiter of nested names, see Config.EnvDelimiter
+}
+
+// ExportWithPrefix creates an ExportOption that sets a prefix for environment variables,
+// it should be the same as `Config.EnvPrefix` of the loader that reads them.
+func ExportWithPrefix(prefix string) ExportOption {
+	return func(opts *exportOptions) { opts.prefix = prefix }
+}
+
+// ExportWithDelimiter creates an ExportOption that sets the delimiter of nested names of environment
+// variables, it should be the same as `Config.EnvDelimiter` of the loader that reads them.
+func ExportWithDelimiter(delimiter string) ExportOption {
+	return func(opts *exportOptions) { opts.envDelimiter = delimiter }
+}
+
+// ExportOmitDefaults creates an ExportOption that omits fields whose values are equal to their
+// "default" tags, so the exported file only holds values that differ from defaults.
+func ExportOmitDefaults() ExportOption {
+	return func(opts *exportOptions) { opts.omitDefaults = true }
+}
+
+// ExportWithSecrets creates an ExportOption that exports values of secret fields (see SecretTag
+// and Secret) as is. By default, they are replaced with RedactedValue, so the exported file
+// does not reproduce them.
+func ExportWithSecrets() ExportOption {
+	return func(opts *exportOptions) { opts.secrets = true }
+}
+
+// Export serializes the loaded configuration of the destination in the provided format into the writer,
+// so it can be attached to a support ticket or fed back to reproduce the same configuration.
+//
+// Keys honour the same tags the loaders read:
+//   - FormatJSON uses `json` tags (like a MapSource), so the file can be used as a MapSource.
+//   - FormatYAML and FormatTOML use `yaml` and `toml` tags respectively, and fall back to `json` tags.
+//   - Values with the text form (e.g. time.Duration, net.IPNet or types implementing encoding.TextMarshaler)
+//     are written as strings in every format, the way the loaders parse them.
+//   - FormatEnv uses `env` tags (and Go names of fields without them) joined by envDelimiter, like the
+//     env parser resolves them, so lines of the file can be passed as `Config.Envs`. The env parser
+//     never unquotes values, so values that span several lines can not be exported in this format.
+//
+// Fields without a tag use their Go names, fields tagged with "-" are omitted. Values of secret fields
+// are redacted, unless ExportWithSecrets is provided.
+//
+// Example usage:
+//
+//	err := gonfig.Export(&cfg, gonfig.FormatYAML, file, gonfig.ExportOmitDefaults())
+func Export(dest any, format string, w io.Writer, options ...ExportOption) error {
+	var opts exportOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	if err := writeConfig(w, dest, format, opts); err != nil {
+		return fmt.Errorf("gonfig: could not export: %w", err)
+	}
+
+	return nil
+}
+
+// exportNode is a node of the rendered configuration, either a leaf with the value of a field
+// or a nested struct with its children in the order of declaration.
+type exportNode struct {
+	name     string
+	value    any
+	origin   string // source of the value, see FieldSource.origin
+	children []*exportNode
+}
+
+// child returns the child with the provided name, it is created if it does not exist yet.
+func (n *exportNode) child(name string) *exportNode {
+	for _, item := range n.children {
+		if item.name == name {
+			return item
+		}
+	}
+
+	item := &exportNode{name: name}
+	n.children = append(n.children, item)
+
+	return item
+}
+
+// exportEntry is a leaf of the rendered configuration.
+type exportEntry struct {
+	elem   *ReflectValue
+	value  any
+	origin string
+}
+
+// exportEntries returns leaves of the destination with their values prepared for rendering
+// (secret values are redacted) and sources of values, if they are known.
+func exportEntries(dest any, options exportOptions) ([]exportEntry, error) {
+	origins := make(map[string]string, len(options.sources))
+	for _, source := range options.sources {
+		origins[source.Path] = source.origin()
+	}
+
+	defaults := make(map[string]any)
+	if rv := reflect.ValueOf(dest); options.omitDefaults && rv.Kind() == reflect.Ptr {
+		scratch := reflect.New(rv.Type().Elem()).Interface()
+		if err := SetDefaults(scratch); err != nil {
+			return nil, err
+		}
+
+		for elem, err := range ReflectFieldsOf(scratch, treeOptions) {
+			if err != nil {
+				return nil, err
+			}
+
+			if elem.Field.Tag.Get(defaultTagName) != "" {
+				defaults[elem.Path()] = elem.Value.Interface()
+			}
+		}
+	}
+
+	var out []exportEntry
+	for elem, err := range ReflectFieldsOf(dest, treeOptions) {
+		if err != nil {
+			return nil, err
+		}
+
+		path := elem.Path()
+		if value, ok := defaults[path]; ok && reflect.DeepEqual(value, elem.Value.Interface()) {
+			continue
+		}
+
+		out = append(out, exportEntry{elem: elem, value: exportValue(elem, options.secrets), origin: origins[path]})
+	}
+
+	return out, nil
+}
+
+// exportValue returns the value of the field for rendering: values of secret fields are redacted,
+// unless secrets are revealed, and values of Secret fields are unwrapped.
+func exportValue(elem *ReflectValue, secrets bool) any {
+	if !secrets && isSecret(elem) {
+		return RedactedValue
+	}
+
+	return secretOf(elem.Value).Interface()
+}
+
+// exportTree builds the nested tree of the rendered configuration, keys are resolved by the first
+// of the provided tags that names the field, see exportName.
+func exportTree(entries []exportEntry, tags ...string) *exportNode {
+	root := &exportNode{}
+
+loop:
+	for _, entry := range entries {
+		node := root
+		for _, item := range chainOf(entry.elem) {
+			name, ok := exportName(item.Field, tags)
+			if !ok {
+				continue loop
+			}
+
+			if name != "" {
+				node = node.child(name)
+			}
+		}
+
+		node.value, node.origin = entry.value, entry.origin
+	}
+
+	return root
+}
+
+// exportName returns the key of the field from the first of the provided tags that names it, or
+// the Go name of the field. Embedded structs without tags are squashed, so their key is empty.
+// It returns false if the field is omitted by the tag "-".
+func exportName(field reflect.StructField, tags []string) (string, bool) {
+	for _, tag := range tags {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		switch {
+		case name == "-":
+			return "", false
+		case name != "":
+			return name, true
+		}
+	}
+
+	if field.Anonymous {
+		return "", true
+	}
+
+	return field.Name, true
+}
+
+// chainOf returns the field and all its owners, starting from the top-level field.
+func chainOf(elem *ReflectValue) []*ReflectValue {
+	var chain []*ReflectValue
+	for item := elem; item != nil && item.Owner != nil; item = item.Owner {
+		chain = append([]*ReflectValue{item}, chain...)
+	}
+
+	return chain
+}
+
+// writeConfig renders the configuration of the destination in the provided format into the writer.
+// Sources of values are rendered as comments in YAML, TOML and env formats, and as the separate
+// "sources" object in JSON format, which has no comments.
+func writeConfig(w io.Writer, dest any, format string, options exportOptions) error {
+	entries, err := exportEntries(dest, options)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, exportTree(entries, mapSourceTag), entries)
+	case FormatYAML:
+		return writeYAML(w, exportTree(entries, FormatYAML, mapSourceTag))
+	case FormatTOML:
+		return writeTOML(w, exportTree(entries, FormatTOML, mapSourceTag))
+	case FormatEnv:
+		return writeEnv(w, entries, options)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// writeJSON renders the tree as a JSON object, keeping the order of fields. Sources of values
+// are rendered next to the configuration, keyed by paths of fields.
+func writeJSON(w io.Writer, root *exportNode, entries []exportEntry) error {
+	var buf bytes.Buffer
+	if err := root.json(&buf); err != nil {
+		return err
+	}
+
+	origins := make(map[string]string)
+	for _, entry := range entries {
+		if entry.origin != "" {
+			origins[entry.elem.Path()] = entry.origin
+		}
+	}
+
+	if len(origins) > 0 {
+		data, err := marshalJSON(origins)
+		if err != nil {
+			return err
+		}
+
+		buf = *bytes.NewBufferString(`{"config":` + buf.String() + `,"sources":` + string(data) + `}`)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return err
+	}
+
+	out.WriteByte('\n')
+
+	_, err := out.WriteTo(w)
+
+	return err
+}
+
+// json writes the node as JSON into the buffer. Values with the text form (see exportText) are written
+// as strings, like they are in TOML and env, so the file can be loaded back (e.g. as a MapSource).
+func (n *exportNode) json(buf *bytes.Buffer) error {
+	if n.children == nil {
+		value, err := plainValue(reflect.ValueOf(n.value))
+		if err != nil {
+			return fmt.Errorf("could not encode %q: %w", n.name, err)
+		}
+
+		data, err := marshalJSON(value)
+		if err != nil {
+			return fmt.Errorf("could not encode %q: %w", n.name, err)
+		}
+
+		buf.Write(data)
+
+		return nil
+	}
+
+	buf.WriteByte('{')
+	for i, item := range n.children {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		name, _ := marshalJSON(item.name)
+		buf.Write(name)
+		buf.WriteByte(':')
+
+		if err := item.json(buf); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteByte('}')
+
+	return nil
+}
+
+// plainValue replaces values with the text form (see exportText) by their text, in items of slices
+// and values of maps as well, for JSON and YAML. Other values are returned as is and encoded by
+// encoding/json or yaml.v3.
+func plainValue(value reflect.Value) (any, error) {
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, nil
+		}
+
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		return nil, nil
+	}
+
+	if text, ok, err := exportText(value); err != nil || ok {
+		return text, err
+	}
+
+	switch {
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8:
+		return value.Interface(), nil
+	case value.Kind() == reflect.Slice && value.IsNil():
+		return nil, nil
+	case value.Kind() == reflect.Slice, value.Kind() == reflect.Array:
+		out := make([]any, 0, value.Len())
+		for i := range value.Len() {
+			item, err := plainValue(value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, item)
+		}
+
+		return out, nil
+	case value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String:
+		if value.IsNil() {
+			return nil, nil
+		}
+
+		out := make(map[string]any, value.Len())
+		for iter := value.MapRange(); iter.Next(); {
+			item, err := plainValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+
+			out[iter.Key().String()] = item
+		}
+
+		return out, nil
+	default:
+		return value.Interface(), nil
+	}
+}
+
+// marshalJSON encodes the value like json.Marshal, but does not escape HTML characters.
+func marshalJSON(value any) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// writeYAML renders the tree as a YAML document, sources of values are rendered as line comments.
+func writeYAML(w io.Writer, root *exportNode) error {
+	doc, err := root.yaml()
+	if err != nil {
+		return err
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err = enc.Encode(doc); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// yaml converts the tree into YAML nodes, keeping the order of fields.
+func (n *exportNode) yaml() (*yaml.Node, error) {
+	if n.children == nil {
+		value, err := plainValue(reflect.ValueOf(n.value))
+		if err != nil {
+			return nil, fmt.Errorf("could not encode %q: %w", n.name, err)
+		}
+
+		node := new(yaml.Node)
+		if err = node.Encode(value); err != nil {
+			return nil, fmt.Errorf("could not encode %q: %w", n.name, err)
+		}
+
+		if node.Kind == yaml.ScalarNode {
+			node.LineComment = n.origin
+		}
+
+		return node, nil
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, item := range n.children {
+		value, err := item.yaml()
+		if err != nil {
+			return nil, err
+		}
+
+		// comments of collections are rendered next to their keys, otherwise they follow the last item.
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: item.name}
+		if value.Kind != yaml.ScalarNode {
+			key.LineComment = item.origin
+		}
+
+		node.Content = append(node.Content, key, value)
+	}
+
+	return node, nil
+}
+
+// tomlBareKey matches keys that can be written in TOML without quotes.
+var tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// writeTOML renders the tree as a TOML document. Keys of every table go first, then nested
+// structs are rendered as tables, maps are rendered as inline tables. Nil values are omitted,
+// because TOML has no null. Sources of values are rendered as line comments.
+func writeTOML(w io.Writer, root *exportNode) error {
+	var buf bytes.Buffer
+	if err := root.toml(&buf, nil); err != nil {
+		return err
+	}
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+// toml writes keys of the table into the buffer, and then its nested tables.
+func (n *exportNode) toml(buf *bytes.Buffer, path []string) error {
+	var (
+		tables []*exportNode
+		header = len(path) == 0
+	)
+
+	for _, item := range n.children {
+		if item.children != nil {
+			tables = append(tables, item)
+
+			continue
+		}
+
+		value, ok, err := tomlValue(reflect.ValueOf(item.value))
+		if err != nil {
+			return fmt.Errorf("could not encode %q: %w", item.name, err)
+		} else if !ok {
+			continue
+		}
+
+		if !header {
+			if buf.Len() > 0 {
+				buf.WriteByte('\n')
+			}
+
+			buf.WriteString("[" + strings.Join(path, ".") + "]\n")
+			header = true
+		}
+
+		buf.WriteString(tomlKey(item.name) + " = " + value)
+		if item.origin != "" {
+			buf.WriteString(" # " + item.origin)
+		}
+
+		buf.WriteByte('\n')
+	}
+
+	for _, item := range tables {
+		if err := item.toml(buf, append(slices.Clone(path), tomlKey(item.name))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tomlKey returns the key as is, if it is bare, or quoted otherwise.
+func tomlKey(key string) string {
+	if tomlBareKey.MatchString(key) {
+		return key
+	}
+
+	return tomlString(key)
+}
+
+// tomlString quotes the string as a TOML basic string.
+func tomlString(value string) string {
+	var buf strings.Builder
+
+	buf.WriteByte('"')
+	for _, r := range value {
+		switch {
+		case r == '"' || r == '\\':
+			buf.WriteString(`\` + string(r))
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			_, _ = fmt.Fprintf(&buf, `\u%04X`, r)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+
+	buf.WriteByte('"')
+
+	return buf.String()
+}
+
+// tomlValue encodes the value as a TOML value, it returns false for nil values.
+func tomlValue(value reflect.Value) (string, bool, error) {
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "", false, nil
+		}
+
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		return "", false, nil
+	}
+
+	if text, ok, err := exportText(value); err != nil || ok {
+		return tomlString(text), err == nil, err
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return tomlString(value.String()), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), true, nil
+	case reflect.Float32, reflect.Float64:
+		return tomlFloat(value.Float()), true, nil
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, value.Len())
+		for i := range value.Len() {
+			item, ok, err := tomlValue(value.Index(i))
+			if err != nil {
+				return "", false, err
+			} else if ok {
+				items = append(items, item)
+			}
+		}
+
+		return "[" + strings.Join(items, ", ") + "]", true, nil
+	case reflect.Map:
+		items := make([]string, 0, value.Len())
+		for _, key := range sortedKeys(value) {
+			item, ok, err := tomlValue(value.MapIndex(key))
+			if err != nil {
+				return "", false, err
+			} else if ok {
+				items = append(items, tomlKey(fmt.Sprint(key.Interface()))+" = "+item)
+			}
+		}
+
+		if len(items) == 0 {
+			return "{}", true, nil
+		}
+
+		return "{ " + strings.Join(items, ", ") + " }", true, nil
+	default:
+		return "", false, fmt.Errorf("unsupported type %s", value.Type())
+	}
+}
+
+// tomlFloat formats the float, so it is never decoded as an integer.
+func tomlFloat(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "nan"
+	case math.IsInf(value, 1):
+		return "inf"
+	case math.IsInf(value, -1):
+		return "-inf"
+	}
+
+	out := strconv.FormatFloat(value, 'g', -1, 64)
+	if !strings.ContainsAny(out, ".e") {
+		out += ".0"
+	}
+
+	return out
+}
+
+// exportText returns the text of values that are not basic types, but have a text form, e.g.
+// time.Duration, net.IP (encoding.TextMarshaler) or net.IPNet (fmt.Stringer).
+func exportText(value reflect.Value) (string, bool, error) {
+	if value.Type() == reflect.TypeFor[time.Duration]() {
+		return value.Interface().(time.Duration).String(), true, nil
+	}
+
+	ptr := reflect.New(value.Type())
+	ptr.Elem().Set(value)
+
+	switch item := ptr.Interface().(type) {
+	case encoding.TextMarshaler:
+		text, err := item.MarshalText()
+
+		return string(text), true, err
+	case fmt.Stringer:
+		if kind := value.Kind(); kind == reflect.Struct || kind == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8 {
+			return item.String(), true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// writeEnv renders entries as environment variables, every variable is preceded by the comment
+// with the source of its value. Slices are joined by commas, keys of maps are appended to the name.
+func writeEnv(w io.Writer, entries []exportEntry, options exportOptions) error {
+	delimiter := Config{EnvDelimiter: options.envDelimiter}.envNesting()
+
+	prefix := options.prefix
+	if prefix != "" {
+		prefix += envDelimiter
+	}
+
+	for _, entry := range entries {
+		name, ok := envName(entry.elem, options.autoEnv, delimiter)
+		if !ok {
+			continue
+		}
+
+		lines, err := envLines(prefix+name, reflect.ValueOf(entry.value), delimiter, false)
+		if err != nil {
+			return fmt.Errorf("could not encode %q: %w", entry.elem.Path(), err)
+		} else if len(lines) == 0 {
+			continue
+		}
+
+		if entry.origin != "" {
+			lines = append([]string{"# " + entry.origin}, lines...)
+		}
+
+		if _, err = io.WriteString(w, strings.Join(lines, "\n")+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// envName builds the name of the environment variable of the field like the env parser resolves it:
+// from `env` tags or upper-cased Go names of the field and all its owners, joined by envDelimiter.
+// When auto is set, Go names are converted by envAutoName instead, see Config.AutoEnv.
+// It returns false if the field or any of its owners is tagged with `env:"-"`.
+func envName(elem *ReflectValue, auto bool, delimiter string) (string, bool) {
+	var parts []string
+	for _, item := range chainOf(elem) {
+		name, ok := exportName(item.Field, []string{envTag})
+		switch {
+		case !ok:
+			return "", false
+		case name == item.Field.Name && item.Field.Tag.Get(envTag) == "" && auto:
+			parts = append(parts, envAutoName(name))
+		case name == item.Field.Name && item.Field.Tag.Get(envTag) == "":
+			parts = append(parts, strings.ToUpper(name))
+		case name != "":
+			parts = append(parts, name)
+		}
+	}
+
+	return strings.Join(parts, delimiter), true
+}
+
+// envLines renders the value as lines of environment variables with the provided name,
+// keys of maps are appended to the name with the delimiter. Values that span several lines are
+// reported as errors, unless multiline is set: then a single NAME=value item is rendered, its value
+// keeps the line breaks, so it can be passed in `Config.Envs` (see ToEnvs), but not written to a file.
+// Nil slices and zero structs are omitted, so they are not decoded as empty ones or as "<nil>".
+// Items of slices that contain commas can not be read back, so they are reported as errors.
+func envLines(name string, value reflect.Value, delimiter string, multiline bool) ([]string, error) {
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, nil
+		}
+
+		value = value.Elem()
+	}
+
+	if !value.IsValid() || unsetValue(value) {
+		return nil, nil
+	}
+
+	if text, ok, err := exportText(value); err != nil {
+		return nil, err
+	} else if ok {
+		return envLine(name, text, multiline)
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+		var out []string
+		for _, key := range sortedKeys(value) {
+			lines, err := envLines(name+delimiter+fmt.Sprint(key.Interface()), value.MapIndex(key), delimiter, multiline)
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, lines...)
+		}
+
+		return out, nil
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, value.Len())
+		for i := range value.Len() {
+			item := value.Index(i)
+			text, ok, err := exportText(item)
+			if err != nil {
+				return nil, err
+			} else if !ok {
+				text = fmt.Sprint(item.Interface())
+			}
+
+			if strings.Contains(text, ",") {
+				return nil, fmt.Errorf("item %d of the slice contains a comma, it can not be read back", i)
+			}
+
+			items = append(items, text)
+		}
+
+		return envLine(name, strings.Join(items, ","), multiline)
+	default:
+		return envLine(name, fmt.Sprint(value.Interface()), multiline)
+	}
+}
+
+// envLine renders the variable with the value, the value is reported as an error if it spans
+// several lines and multiline is not set, because the env parser never unquotes values.
+func envLine(name, value string, multiline bool) ([]string, error) {
+	if !multiline && strings.ContainsAny(value, "\r\n") {
+		return nil, fmt.Errorf("value spans several lines, it can not be written as a single line")
+	}
+
+	return []string{name + envPairDelim + value}, nil
+}
+
+// sortedKeys returns keys of the map sorted by their string representation, so the output is stable.
+func sortedKeys(value reflect.Value) []reflect.Value {
+	keys := value.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		return strings.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+	})
+
+	return keys
+}
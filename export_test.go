@@ -0,0 +1,156 @@
+package gonfig_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/im-kulikov/gonfig"
+)
+
+type ExportConfig struct {
+	Address  string                `json:"address" yaml:"address" env:"ADDRESS" default:":8080"`
+	Timeout  time.Duration         `json:"timeout" yaml:"timeout" env:"TIMEOUT" default:"5s"`
+	Hosts    []string              `json:"hosts" yaml:"hosts" env:"HOSTS"`
+	Labels   map[string]string     `json:"labels" yaml:"labels" env:"LABELS"`
+	Password gonfig.Secret[string] `json:"password" yaml:"password" env:"PASSWORD"`
+	Token    string                `json:"token" yaml:"token" env:"TOKEN" secret:"true"`
+	IP       net.IP                `json:"ip" yaml:"ip" env:"IP"`
+	Network  net.IPNet             `json:"network" yaml:"network" env:"NETWORK"`
+	Ignored  string                `json:"-" yaml:"-" toml:"-" env:"-"`
+
+	Database struct {
+		Host     string `json:"host" yaml:"host" env:"HOST"`
+		MaxConns int    `json:"max_conns" yaml:"max_conns" toml:"max-conns" env:"MAX_CONNS" default:"10"`
+	} `json:"db" yaml:"db" env:"DB"`
+}
+
+func exportConfig() ExportConfig {
+	cfg := ExportConfig{
+		Address:  ":9090",
+		Timeout:  5 * time.Second,
+		Hosts:    []string{"a", "b"},
+		Labels:   map[string]string{"team": "core"},
+		Password: gonfig.NewSecret("p@ss"),
+		Token:    "token",
+		IP:       net.ParseIP("10.0.0.1"),
+	}
+
+	_, network, _ := net.ParseCIDR("10.0.0.0/8")
+	cfg.Network = *network
+
+	cfg.Database.Host = "db"
+	cfg.Database.MaxConns = 10
+
+	return cfg
+}
+
+func TestExport(t *testing.T) {
+	cfg := exportConfig()
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, gonfig.Export(&cfg, gonfig.FormatJSON, &buf, gonfig.ExportWithSecrets()))
+
+		var raw map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &raw))
+		require.Equal(t, "5s", raw["timeout"])
+		require.Equal(t, "10.0.0.0/8", raw["network"])
+
+		var out ExportConfig
+		require.NoError(t, gonfig.New(gonfig.Config{SkipEnv: true, SkipFlags: true},
+			gonfig.WithMapSource(&mapSource{name: "file", values: raw})).Load(&out))
+		require.Equal(t, cfg, out, "exported file can be used as a map source")
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, gonfig.Export(&cfg, gonfig.FormatYAML, &buf, gonfig.ExportWithSecrets()))
+
+		var raw map[string]any
+		require.NoError(t, yaml.Unmarshal(buf.Bytes(), &raw))
+		require.Equal(t, "10.0.0.0/8", raw["network"])
+
+		var out ExportConfig
+		require.NoError(t, gonfig.New(gonfig.Config{SkipEnv: true, SkipFlags: true},
+			gonfig.WithMapSource(&mapSource{name: "file", values: raw})).Load(&out))
+		require.Equal(t, cfg, out, "exported file can be used as a map source")
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, gonfig.Export(&cfg, gonfig.FormatTOML, &buf))
+		require.Equal(t, `address = ":9090"
+timeout = "5s"
+hosts = ["a", "b"]
+labels = { team = "core" }
+password = "******"
+token = "******"
+ip = "10.0.0.1"
+network = "10.0.0.0/8"
+
+[db]
+host = "db"
+max-conns = 10
+`, buf.String())
+	})
+
+	t.Run("env", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, gonfig.Export(&cfg, gonfig.FormatEnv, &buf, gonfig.ExportWithSecrets(), gonfig.ExportWithPrefix("APP")))
+		require.Equal(t, `APP_ADDRESS=:9090
+APP_TIMEOUT=5s
+APP_HOSTS=a,b
+APP_LABELS_team=core
+APP_PASSWORD=p@ss
+APP_TOKEN=token
+APP_IP=10.0.0.1
+APP_NETWORK=10.0.0.0/8
+APP_DB_HOST=db
+APP_DB_MAX_CONNS=10
+`, buf.String())
+
+		var out ExportConfig
+		require.NoError(t, gonfig.New(gonfig.Config{
+			EnvPrefix: "APP",
+			Envs:      strings.Split(strings.TrimSpace(buf.String()), "\n"),
+			Args:      []string{},
+		}).Load(&out))
+		require.Equal(t, cfg, out)
+	})
+
+	t.Run("omit defaults", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, gonfig.Export(&cfg, gonfig.FormatYAML, &buf, gonfig.ExportOmitDefaults()))
+		require.Equal(t, `address: :9090
+hosts:
+  - a
+  - b
+labels:
+  team: core
+password: '******'
+token: '******'
+ip: 10.0.0.1
+network: 10.0.0.0/8
+db:
+  host: db
+`, buf.String())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		require.EqualError(t, gonfig.Export(&cfg, "xml", new(bytes.Buffer)),
+			`gonfig: could not export: unknown format "xml"`)
+		require.EqualError(t, gonfig.Export(cfg, gonfig.FormatJSON, new(bytes.Buffer)),
+			`gonfig: could not export: expect pointer, got "struct"`)
+		require.EqualError(t, gonfig.Export(&ExportConfig{Hosts: []string{"a,b"}}, gonfig.FormatEnv, new(bytes.Buffer)),
+			`gonfig: could not export: could not encode "Hosts": item 0 of the slice contains a comma, it can not be read back`)
+		require.EqualError(t, gonfig.Export(&ExportConfig{Address: "a\nb"}, gonfig.FormatEnv, new(bytes.Buffer)),
+			`gonfig: could not export: could not encode "Address": value spans several lines, it can not be written as a single line`)
+	})
+}
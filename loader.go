@@ -1,8 +1,19 @@
 package gonfig
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"maps"
 	"os"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
 )
 
 // constantError is a custom error type based on a string.
@@ -18,7 +29,7 @@ type constantError string
 // - SkipFlags: If true, the loader will skip loading configurations from command-line flags.
 //
 //   - EnvPrefix: A string that specifies a prefix for filtering environment variables. Only variables
-//     starting with this prefix will be considered.
+//     starting with this prefix followed by "_" will be considered (see also EnvPrefixes).
 //
 //   - LoaderOrder: Defines the order in which the parsers (defaults, env, flags) will be executed.
 //     This allows prioritization of certain parsers over others.
@@ -51,6 +62,36 @@ type Config struct {
 
 	EnvPrefix string // EnvPrefix for environment variables.
 
+	// EnvPrefixes lists additional prefixes of environment variables in order of precedence, e.g.
+	// {"BILLING", "PLATFORM"} to let service-specific `BILLING_PORT` override shared `PLATFORM_PORT`.
+	// EnvPrefix, if set, takes precedence over all of them. By default, is nil.
+	EnvPrefixes []string
+
+	// AutoEnv set to true will derive names of environment variables of fields without the `env` tag
+	// from their Go names in SCREAMING_SNAKE case, joined along the path of nested structs, e.g. field
+	// `MaxConns` of the struct `Database` is loaded from `DATABASE_MAX_CONNS`. Explicit tags are
+	// respected and fields tagged with `env:"-"` are skipped. By default, is false.
+	AutoEnv bool
+
+	// EnvDelimiter separates names of nested structs (and keys of maps) from names of their fields
+	// in names of environment variables, e.g. "__" for `DATABASE__MAX_CONNS`, so underscores inside
+	// names are not treated as nesting. The EnvPrefix is always followed by "_".
+	// By default, is empty and then "_" is used.
+	EnvDelimiter string
+
+	// EnvSchema set to true resolves environment variables by full names of fields, built from the
+	// schema of the destination, instead of guessing the nesting from delimiters in names of variables
+	// (see PrepareEnvs). It is deterministic: `MAX_CONNS` never shadows a field named `MAX`, and
+	// variables that do not belong to any field are never decoded. By default, is false.
+	EnvSchema bool
+
+	// LoaderOrder defines the order in which parsers are executed, every next parser overrides
+	// values set by the previous ones. By default, is nil and then the order is
+	// defaults -> env -> config-setter -> custom parsers (in order of registration) -> flags.
+	// When set, it must list every registered parser exactly once, except ParserConfigSet
+	// which is executed first when omitted.
+	LoaderOrder []ParserType
+
 	// Envs hold the environment variable from which envs will be parsed.
 	// By default, is nil and then os.Environ() will be used.
 	Envs []string
@@ -59,6 +100,41 @@ type Config struct {
 	// By default, is nil and then os.Args will be used.
 	// Unless loader.Flags() will be explicitly parsed by the user.
 	Args []string
+
+	// Output is the writer for the help. By default, is nil and then nothing is printed:
+	// the rendered help is only returned as ErrHelp. When ExitOnHelp is set,
+	// the help is printed to os.Stdout if Output is nil.
+	Output io.Writer
+
+	// ExitOnHelp set to true will print the help and terminate the process with exit code 0
+	// when the help flag is provided, instead of returning ErrHelp.
+	ExitOnHelp bool
+}
+
+// envPrefixes returns prefixes of environment variables in order of precedence: EnvPrefix and then
+// EnvPrefixes, without empty and duplicate ones. It returns a single empty prefix if none is set.
+func (c Config) envPrefixes() []string {
+	out := make([]string, 0, len(c.EnvPrefixes)+1)
+	for _, prefix := range append([]string{c.EnvPrefix}, c.EnvPrefixes...) {
+		if prefix != "" && !slices.Contains(out, prefix) {
+			out = append(out, prefix)
+		}
+	}
+
+	if len(out) == 0 {
+		return []string{""}
+	}
+
+	return out
+}
+
+// envNesting returns the delimiter of nested names of environment variables, see EnvDelimiter.
+func (c Config) envNesting() string {
+	if c.EnvDelimiter == "" {
+		return envDelimiter
+	}
+
+	return c.EnvDelimiter
 }
 
 // loader is responsible for managing the configuration loading process by coordinating different parsers.
@@ -74,6 +150,11 @@ type Config struct {
 //     and the values are `Parser` instances. This map allows the loader to invoke the correct parser
 //     based on the order specified in `LoaderOrder` from the `Config`.
 //
+//   - sequence: The validated order of parsers, it is resolved once when the loader is built.
+//
+// The loader is built once by `NewE` and is not modified afterward, so it can be used to load
+// configuration repeatedly and concurrently into different destinations.
+//
 // The `loader` is initialized with a set of defaults and custom options can be added through
 // `LoaderOption` functions. Each parser in the `groups` map is responsible for loading part of the
 // configuration from its respective source (e.g., defaults, environment variables, or flags).
@@ -84,9 +165,28 @@ type Config struct {
 type loader struct {
 	Config
 
-	config string
-	orders []ParserType
-	groups map[ParserType]Parser
+	orders   []ParserType
+	groups   map[ParserType]Parser
+	sequence []ParserType
+	timeouts map[ParserType]time.Duration
+
+	wrapped     map[ParserType]Parser // parsers wrapped with middlewares, resolved once.
+	middlewares []ParserMiddleware
+	running     sync.Map // contexts of parsers running behind middlewares by destination, see layeredLoad.
+	before      []BeforeLoadHook
+	after       []AfterLoadHook
+
+	logger *slog.Logger
+
+	optionals map[ParserType]bool
+	bootPhase []ParserType
+	parallel  map[ParserType]bool
+
+	commands bool // built-in commands are enabled, see WithConfigCommands.
+
+	// setter serializes parsers that implement ParserConfigSetter, because the config path
+	// is set into the parser right before the load and must not be overridden by concurrent loads.
+	setter sync.Mutex
 
 	exit func(int) // used for tests, to ignore os.Exit
 }
@@ -192,8 +292,45 @@ func WithCustomParser(p Parser) LoaderOption {
 			return nil
 		}
 
-		l.groups[p.Type()] = p
-		l.orders = append(l.orders, p.Type())
+		l.register(p)
+
+		return nil
+	}
+}
+
+// WithMapSource creates a LoaderOption that adds the MapSource as a custom parser, see NewMapParser.
+// Values of the source are merged with values of other parsers by precedence and decoded once.
+//
+// Example usage:
+//
+//	gonfig.New(gonfig.Config{}, gonfig.WithMapSource(consulSource))
+func WithMapSource(source MapSource) LoaderOption {
+	return func(l *loader) error {
+		if source == nil {
+			return nil
+		}
+
+		l.register(NewMapParser(source))
+
+		return nil
+	}
+}
+
+// WithOptionalParser creates a LoaderOption that adds a custom parser like WithCustomParser,
+// but marks it as optional: if the parser fails (e.g. a config file is missing or a remote source
+// is unreachable), the failure is logged as a warning (see WithLogger) and loading continues with
+// the next parser. A parser can also declare itself optional by implementing ParserOptional.
+//
+// Values that the failed parser managed to set before the failure stay in the destination.
+// Help requests and cancellation of the whole load are never ignored.
+func WithOptionalParser(p Parser) LoaderOption {
+	return func(l *loader) error {
+		if p == nil {
+			return nil
+		}
+
+		l.register(p)
+		l.optionals[p.Type()] = true
 
 		return nil
 	}
@@ -223,8 +360,7 @@ func WithCustomParserInit(fabric ParserInit) LoaderOption {
 		case parser == nil:
 			return nil
 		default:
-			l.groups[parser.Type()] = parser
-			l.orders = append(l.orders, parser.Type())
+			l.register(parser)
 
 			return nil
 		}
@@ -271,8 +407,46 @@ func WithOptions(options any) LoaderOption {
 	}
 }
 
+// WithOrder creates a LoaderOption that sets the order in which parsers are executed,
+// see Config.LoaderOrder for details. The order is validated against registered parsers
+// when the loader is built: NewE returns the error, a loader created by New returns it from
+// every load.
+//
+// Example usage:
+//
+//	// file values override environment variables
+//	gonfig.New(gonfig.Config{}, gonfig.WithCustomParser(jsonParser),
+//	    gonfig.WithOrder(gonfig.ParserDefaults, gonfig.ParserEnv, "json", gonfig.ParserFlags))
+func WithOrder(order ...ParserType) LoaderOption {
+	return func(l *loader) error { l.LoaderOrder = order; return nil }
+}
+
+// WithParserTimeout creates a LoaderOption that limits the time of a single run of the parser with
+// the provided type. The deadline is applied to the context passed to parsers implementing the
+// ContextParser interface, other parsers can not be interrupted. When the timeout is exceeded,
+// the loading fails with an error that names the parser which timed out.
+//
+// Example usage:
+//
+//	gonfig.New(gonfig.Config{}, gonfig.WithCustomParser(consulParser),
+//	    gonfig.WithParserTimeout("consul", 5*time.Second))
+func WithParserTimeout(typ ParserType, timeout time.Duration) LoaderOption {
+	return func(l *loader) error {
+		if timeout <= 0 {
+			return fmt.Errorf("invalid timeout %s for parser %q", timeout, typ)
+		}
+
+		l.timeouts[typ] = timeout
+
+		return nil
+	}
+}
+
+// WithCustomExit creates a LoaderOption that enables exit on help (see Config.ExitOnHelp) and
+// replaces os.Exit with the provided function. If the function returns, the loading is stopped
+// without an error, which is mostly useful for tests.
 func WithCustomExit(exit func(int)) LoaderOption {
-	return func(l *loader) error { l.exit = exit; return nil }
+	return func(l *loader) error { l.exit = exit; l.ExitOnHelp = true; return nil }
 }
 
 // setLoaderDefaults initializes a loader with default values based on the provided configuration.
@@ -293,7 +467,15 @@ func WithCustomExit(exit func(int)) LoaderOption {
 // Returns:
 // - A pointer to a `loader` struct, which contains the updated Config and the map of available parsers.
 func setLoaderDefaults(c Config) *loader {
-	svc := &loader{Config: c, groups: make(map[ParserType]Parser, 4)}
+	svc := &loader{
+		Config:   c,
+		groups:   make(map[ParserType]Parser, 4),
+		timeouts: make(map[ParserType]time.Duration),
+
+		optionals: make(map[ParserType]bool),
+		parallel:  make(map[ParserType]bool),
+		logger:    slog.New(discardHandler{}),
+	}
 
 	if svc.Envs == nil {
 		svc.Envs = os.Environ()
@@ -308,73 +490,427 @@ func setLoaderDefaults(c Config) *loader {
 	}
 
 	if !svc.SkipEnv {
-		svc.groups[ParserEnv] = newEnvLoader(svc.Envs, svc.EnvPrefix)
+		svc.groups[ParserEnv] = newEnvLoader(svc.Config)
 	}
 
 	if !svc.SkipFlags {
 		svc.groups[ParserFlags] = newFlagsLoader(svc.Args)
-		svc.groups[ParserConfigSet] = parseConfigPath(svc)
+		svc.groups[ParserConfigSet] = parseConfigPath(svc.Args)
 	}
 
 	return svc
 }
 
 // New creates a new Parser based on the provided configuration and optional LoaderOptions.
+// It is a shorthand for `NewE`: if the loader can not be built, the returned Parser reports the
+// error on every call of `Load`.
+//
+// Parameters:
+// - config: The Config object used to initialize the default settings for the loader.
+// - options: A variadic number of LoaderOption functions to customize the loader.
+//
+// Returns:
+// - A Loader that can be used to load and parse values into the provided target structure.
+func New(config Config, options ...LoaderOption) Loader {
+	parser, err := NewE(config, options...)
+	if err != nil {
+		return &loaderParser{contextParserFunc: contextParserFunc{call: func(context.Context, any) error { return err }}}
+	}
+
+	return parser
+}
+
+// NewE creates a new Parser based on the provided configuration and optional LoaderOptions.
 // The function initializes a loader service (`svc`) with default settings from the provided
-// configuration. Then it applies each LoaderOption to customize the service if any are provided.
+// configuration. Then it applies each LoaderOption to customize the service and validates the
+// order of parsers. Options are applied only once, so any error is returned immediately.
+//
+// The function returns a `Loader` that, when called, will:
+// - Iterate through the resolved order and invoke the corresponding group parsers.
+// - Validate required fields of the target structure.
+// If any parser fails, the function returns an error.
 //
-// The function returns a `parserFunc` that, when called, will:
-// - Apply all the LoaderOptions to the `svc`.
-// - Iterate through the `LoaderOrder` and invoke the corresponding group parsers.
-// If any parser fails or if a group parser is missing, the function returns an error.
+// The returned Loader does not share any state between calls, so it can be used repeatedly and
+// concurrently to load configuration into different destinations. Sources of fields are returned by
+// Loader.LoadExplain. Its LoadContext passes the context to parsers that implement the ContextParser
+// interface, see WithParserTimeout for per-parser deadlines.
 //
 // Parameters:
 // - config: The Config object used to initialize the default settings for the loader.
 // - options: A variadic number of LoaderOption functions to customize the loader.
 //
 // Returns:
-// - A Parser that can be used to load and parse values into the provided target structure.
-func New(config Config, options ...LoaderOption) Parser {
+// - A Loader that can be used to load and parse values into the provided target structure.
+// - An error if any option fails or the order of parsers is invalid.
+func NewE(config Config, options ...LoaderOption) (Loader, error) {
 	svc := setLoaderDefaults(config)
 
+	for _, option := range options {
+		if err := option(svc); err != nil {
+			return nil, fmt.Errorf("gonfig: could not init option: %w", err)
+		}
+	}
+
+	var err error
+	if svc.sequence, err = svc.order(); err != nil {
+		return nil, fmt.Errorf("gonfig: could not prepare order: %w", err)
+	}
+
+	svc.wrapParsers()
+	svc.logFallbacks(config)
+
 	// return group parser
-	return &parserFunc{call: wrapUsageLoader(svc, func(v interface{}) error {
-		for _, option := range options {
-			if err := option(svc); err != nil {
-				return fmt.Errorf("gonfig: could not init option: %w", err)
+	return &loaderParser{contextParserFunc: contextParserFunc{call: wrapUsageLoader(svc, svc.load)}, svc: svc}, nil
+}
+
+// Load creates a loader with the provided configuration and options (see NewE) and loads a new
+// value of type T with it, so defaults, environment variables, flags, custom parsers and validation
+// behave exactly like `New(config, options...).Load(&dest)`.
+//
+// T must be a struct type. Go generics can not express this constraint, so it is checked before
+// the loader is built and ErrExpectStruct is returned for any other type.
+//
+// Example usage:
+//
+//	cfg, err := gonfig.Load[Config](gonfig.Config{EnvPrefix: "APP"})
+func Load[T any](config Config, options ...LoaderOption) (T, error) {
+	var dest T
+	if kind := reflect.TypeFor[T]().Kind(); kind != reflect.Struct {
+		return dest, fmt.Errorf("gonfig: %w, got %q", ErrExpectStruct, kind)
+	}
+
+	parser, err := NewE(config, options...)
+	if err != nil {
+		return dest, err
+	}
+
+	if err = parser.Load(&dest); err != nil {
+		return dest, err
+	}
+
+	return dest, nil
+}
+
+// MustLoad works like Load, but panics if the configuration can not be loaded.
+// It is intended for the application entry point, where a broken configuration is fatal.
+//
+// Example usage:
+//
+//	cfg := gonfig.MustLoad[Config](gonfig.Config{})
+func MustLoad[T any](config Config, options ...LoaderOption) T {
+	dest, err := Load[T](config, options...)
+	if err != nil {
+		panic(err)
+	}
+
+	return dest
+}
+
+// register adds the parser to the loader's group of parsers. The parser replaces a previously
+// registered parser with the same type, but keeps its position in the order of custom parsers.
+func (l *loader) register(p Parser) {
+	if _, ok := l.groups[p.Type()]; !ok {
+		l.orders = append(l.orders, p.Type())
+	}
+
+	l.groups[p.Type()] = p
+}
+
+// load invokes all parsers in the resolved order to load the configuration into the provided
+// destination and validates required fields. The config path is resolved for every call
+// separately, so concurrent calls do not share any state.
+//
+// If bootstrap parsers are registered, the bootstrap phase is executed first, see bootstrap.
+// If commands are requested (see WithConfigCommands), they are executed after the chain of parsers.
+//
+// The context is passed to parsers that implement ContextParser, limited by the per-parser
+// timeout if it is set. If the context is done, the error reports which parser was interrupted.
+func (l *loader) load(ctx context.Context, v any) error {
+	_, err := l.explain(ctx, v)
+
+	return err
+}
+
+// explain works like load, but also returns sources of the fields set by the load, see Loader.LoadExplain.
+func (l *loader) explain(ctx context.Context, v any) ([]FieldSource, error) {
+	var commands configCommands
+	if l.commands {
+		var err error
+		if commands, err = parseCommands(l.Args); err != nil {
+			return nil, fmt.Errorf("gonfig: could not load: %w", err)
+		}
+	}
+
+	parsers, err := l.bootstrap(ctx, v)
+
+	state := l.newLayers(v, parsers)
+	if err == nil {
+		err = l.execute(ctx, state, l.sequence)
+	}
+
+	var help *ErrHelp
+	switch {
+	case errors.As(err, &help):
+		return nil, err
+	case commands.check:
+		return nil, l.checkConfig(v, err)
+	case err != nil:
+		return nil, err
+	}
+
+	sources := state.explain()
+
+	if !l.SkipEnv {
+		l.logIgnoredEnvs(ctx, state)
+	}
+
+	if commands.print != "" {
+		return sources, l.printConfig(v, commands.print, sources)
+	}
+
+	if err = ValidateRequiredFields(v); err != nil {
+		l.logger.LogAttrs(ctx, slog.LevelError, "gonfig: validation failed", slog.Any("error", err))
+
+		return sources, err
+	}
+
+	return sources, nil
+}
+
+// execute invokes parsers of the provided sequence to load the configuration into the destination
+// of the layers. Parsers created for the current call (see bootstrap) take precedence over the
+// registered ones. Sources of fields set by parsers are recorded into the layers, see Loader.LoadExplain.
+func (l *loader) execute(ctx context.Context, state *layers, sequence []ParserType) error {
+	v := state.dest
+	ctx = withLogger(withLayers(ctx, state), l.logger)
+
+	var path string
+	for i := 0; i < len(sequence); i++ {
+		if l.parallel[sequence[i]] {
+			next := i + 1
+			for next < len(sequence) && l.parallel[sequence[next]] {
+				next++
+			}
+
+			if err := l.executeParallel(ctx, state, sequence[i:next], path, v); err != nil {
+				return err
 			}
+
+			i = next - 1
+
+			continue
+		}
+
+		typ := sequence[i]
+		parser, wrapped, ok := l.resolve(typ, state.parsers)
+		if !ok {
+			continue
+		}
+
+		// pending layers are decoded before parsers that write into the destination directly
+		// or read values from it, so every next parser overrides values of the previous ones.
+		if _, ok := parser.(*configPathParser); !ok && direct(parser) {
+			if err := state.flush(ctx); err != nil {
+				return fmt.Errorf("gonfig: could not load: %w", err)
+			}
+		}
+
+		// parsers that decode values into the destination directly are explained by the changes they made.
+		var before any
+		if _, ok := parser.(*configPathParser); !ok && !contributes(parser) {
+			before = snapshot(v)
 		}
 
-		order := make([]ParserType, 0, len(svc.groups)+4)
+		var err error
+		switch parser := parser.(type) {
+		case *configPathParser:
+			if path, err = parser.lookup(v); err == nil && path != "" {
+				l.logger.LogAttrs(ctx, slog.LevelDebug, "gonfig: config path resolved", slog.String("path", path))
+			}
+		case ParserConfigSetter:
+			l.setter.Lock()
+			parser.SetConfigPath(path)
+			err = l.run(ctx, state, typ, wrapped, v)
+			l.setter.Unlock()
+		default:
+			err = l.run(ctx, state, typ, wrapped, v)
+		}
+
+		if err != nil {
+			if err = l.failure(ctx, state, typ, err); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if before != nil {
+			state.record(typ, before)
+		}
+	}
+
+	if err := state.flush(ctx); err != nil {
+		return fmt.Errorf("gonfig: could not load: %w", err)
+	}
 
-		if !config.SkipDefaults { // set defaults
+	return nil
+}
+
+// direct reports whether the parser can not contribute to the layers of the current load, i.e. it
+// decodes values into the destination directly or reads values from it.
+func direct(parser Parser) bool {
+	if layered, ok := parser.(layeredParser); ok && layered.needsDest() {
+		return true
+	}
+
+	return !contributes(parser)
+}
+
+// contributes reports whether the parser contributes its values to the layers of the current load.
+// Layered parsers contribute even behind middlewares that do not pass the context, see layeredLoad.
+func contributes(parser Parser) bool {
+	_, ok := parser.(layeredParser)
+
+	return ok
+}
+
+// resolve returns the parser of the provided type and its wrapped version. Parsers passed for
+// the current load (see bootstrap) take precedence over registered ones, a nil parser means that
+// it should be skipped, and then false is returned.
+func (l *loader) resolve(typ ParserType, parsers map[ParserType]Parser) (Parser, Parser, bool) {
+	custom, ok := parsers[typ]
+	switch {
+	case !ok:
+		return l.groups[typ], l.wrapped[typ], true
+	case custom == nil:
+		return nil, nil, false // bootstrap factory decided to skip the parser
+	default:
+		return custom, l.wrap(custom), true
+	}
+}
+
+// failure converts the error of the parser into the error of the load. It returns nil when
+// there is no error or the error can be skipped, see skippable.
+func (l *loader) failure(ctx context.Context, state *layers, typ ParserType, err error) error {
+	switch {
+	case err == nil || l.skippable(ctx, state, typ, err):
+		return nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("gonfig: could not load: parser %q timed out: %w", typ, err)
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("gonfig: could not load: parser %q cancelled: %w", typ, err)
+	default:
+		return fmt.Errorf("gonfig: could not load: %w", err)
+	}
+}
+
+// optional reports whether the parser of the provided type is optional, i.e. it was registered
+// with WithOptionalParser or the parser run for the load implements ParserOptional and reports true.
+// The parser is resolved like it is for the run, so a parser created by the bootstrap factory
+// (see WithBootstrapParser) is checked instead of its placeholder.
+func (s *layers) optional(typ ParserType) bool {
+	parser, _, _ := s.loader.resolve(typ, s.parsers)
+	if parser, ok := parser.(ParserOptional); ok && parser.Optional() {
+		return true
+	}
+
+	return s.loader.optionals[typ]
+}
+
+// skippable reports whether the error of the parser can be ignored: the parser must be optional,
+// the error must not be a help request, and the load itself must not be cancelled.
+func (l *loader) skippable(ctx context.Context, state *layers, typ ParserType, err error) bool {
+	return state.optional(typ) && !errors.Is(err, pflag.ErrHelp) && ctx.Err() == nil
+}
+
+// run invokes the parser (wrapped with middlewares) of the provided type with the context limited
+// by its timeout, if any. Registered hooks are called before and after the parser. Hooks inspect
+// the destination, so when there are any, the layer of the parser is decoded right after its run.
+func (l *loader) run(ctx context.Context, state *layers, typ ParserType, parser Parser, v any) error {
+	if timeout, ok := l.timeouts[typ]; ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	for _, hook := range l.before {
+		hook(typ, v)
+	}
+
+	if len(l.middlewares) > 0 && reflect.ValueOf(v).Kind() == reflect.Pointer {
+		l.running.Store(v, ctx)
+		defer l.running.Delete(v)
+	}
+
+	start := time.Now()
+	err := loadContext(ctx, parser, v)
+	if err == nil && len(l.before)+len(l.after) > 0 && state.dest == v {
+		err = state.flush(ctx)
+	}
+
+	took := time.Since(start)
+	l.logRun(ctx, typ, state.optional(typ), took, err)
+
+	for _, hook := range l.after {
+		hook(typ, v, took, err)
+	}
+
+	return err
+}
+
+// order returns the order in which parsers should be executed. If `LoaderOrder` is not set,
+// the default order is used: defaults -> env -> config-setter -> custom parsers -> flags.
+//
+// Otherwise, `LoaderOrder` is validated against registered parsers: every parser must be
+// registered and listed exactly once. `ParserConfigSet` only resolves the config path for
+// parsers that implement `ParserConfigSetter`, so it is executed first when omitted.
+func (l *loader) order() ([]ParserType, error) {
+	if len(l.LoaderOrder) == 0 {
+		order := make([]ParserType, 0, len(l.groups))
+
+		if !l.SkipDefaults { // set defaults
 			order = append(order, ParserDefaults)
 		}
 
-		if !config.SkipEnv { // set envs
+		if !l.SkipEnv { // set envs
 			order = append(order, ParserEnv)
 		}
 
-		if !config.SkipFlags { // set config flag
+		if !l.SkipFlags { // set config flag
 			order = append(order, ParserConfigSet)
 		}
 
-		order = append(order, svc.orders...)
+		order = append(order, l.orders...)
 
-		if !config.SkipFlags { // set flags
+		if !l.SkipFlags { // set flags
 			order = append(order, ParserFlags)
 		}
 
-		for _, typ := range order {
-			if setter, ok := svc.groups[typ].(ParserConfigSetter); ok {
-				setter.SetConfigPath(svc.config)
-			}
+		return order, nil
+	}
 
-			if err := svc.groups[typ].Load(v); err != nil {
-				return fmt.Errorf("gonfig: could not load: %w", err)
-			}
+	order := make([]ParserType, 0, len(l.groups))
+	if _, ok := l.groups[ParserConfigSet]; ok && !slices.Contains(l.LoaderOrder, ParserConfigSet) {
+		order = append(order, ParserConfigSet)
+	}
+
+	for _, typ := range l.LoaderOrder {
+		if _, ok := l.groups[typ]; !ok {
+			return nil, fmt.Errorf("unknown parser %q", typ)
+		}
+
+		if slices.Contains(order, typ) {
+			return nil, fmt.Errorf("parser %q listed more than once", typ)
+		}
+
+		order = append(order, typ)
+	}
+
+	for _, typ := range slices.Sorted(maps.Keys(l.groups)) {
+		if !slices.Contains(order, typ) {
+			return nil, fmt.Errorf("parser %q is not listed", typ)
 		}
+	}
 
-		return ValidateRequiredFields(v)
-	})}
+	return order, nil
 }
@@ -0,0 +1,127 @@
+package gonfig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// BootstrapInit is a function type that allows initializing a Parser which depends on configuration
+// values, e.g. a Consul or Vault source whose address and token are loaded from env or flags.
+// It takes the loader Config and a pointer to the bootstrap configuration, which has the same type as
+// the destination and is loaded by the bootstrap phase (see WithBootstrapPhase). Returning a nil
+// Parser skips the source for the current load.
+type BootstrapInit func(c Config, bootstrap any) (Parser, error)
+
+// bootstrapParser is a placeholder of a parser that is created by BootstrapInit for every load.
+// It keeps the declared type of the parser, so it can take part in the order of parsers.
+type bootstrapParser struct {
+	name ParserType
+	init BootstrapInit
+}
+
+// defaultBootstrapPhase is the default subset of parsers executed in the bootstrap phase.
+var defaultBootstrapPhase = []ParserType{ParserDefaults, ParserEnv, ParserConfigSet, ParserFlags}
+
+// Type returns the declared type of the bootstrap parser.
+func (p *bootstrapParser) Type() ParserType { return p.name }
+
+// Load always fails, the placeholder is replaced with the parser created by BootstrapInit.
+func (p *bootstrapParser) Load(interface{}) error {
+	return fmt.Errorf("(bootstrap) parser %q is not initialized", p.name)
+}
+
+// WithBootstrapParser creates a LoaderOption that registers a parser which is created for every load
+// from the partially loaded configuration. Loading is performed in two phases:
+//
+//  1. The bootstrap phase loads a designated subset of parsers (by default defaults, env and flags,
+//     see WithBootstrapPhase) into a fresh instance of the destination type.
+//  2. Every BootstrapInit receives the bootstrap instance and creates its parser, then the full chain
+//     of parsers is executed into the destination, including the created parsers.
+//
+// The parser is declared with its type, so it can be listed in `LoaderOrder`; by default it is executed
+// among custom parsers, in order of registration. The created parser must have the declared type.
+// The created parser is optional if it implements ParserOptional and reports true.
+//
+// Example usage:
+//
+//	gonfig.New(gonfig.Config{}, gonfig.WithBootstrapParser("consul", func(_ gonfig.Config, boot any) (gonfig.Parser, error) {
+//	    cfg := boot.(*Config)
+//	    return NewConsulParser(cfg.Consul.Address, cfg.Consul.Token)
+//	}))
+func WithBootstrapParser(typ ParserType, fabric BootstrapInit) LoaderOption {
+	return func(l *loader) error {
+		if fabric == nil {
+			return fmt.Errorf("bootstrap parser %q: empty fabric", typ)
+		}
+
+		l.register(&bootstrapParser{name: typ, init: fabric})
+
+		return nil
+	}
+}
+
+// WithBootstrapPhase creates a LoaderOption that designates the subset of parsers executed in the
+// bootstrap phase, see WithBootstrapParser. Parsers are executed in the order of the loader, not in
+// the order of arguments. Bootstrap parsers can not be part of the bootstrap phase.
+func WithBootstrapPhase(types ...ParserType) LoaderOption {
+	return func(l *loader) error {
+		l.bootPhase = types
+
+		return nil
+	}
+}
+
+// bootstrap executes the bootstrap phase and creates parsers of all registered bootstrap parsers.
+// It returns nil if there are no bootstrap parsers, so the loading has only one phase.
+func (l *loader) bootstrap(ctx context.Context, v any) (map[ParserType]Parser, error) {
+	var inits []*bootstrapParser
+	for _, typ := range l.sequence {
+		if parser, ok := l.groups[typ].(*bootstrapParser); ok {
+			inits = append(inits, parser)
+		}
+	}
+
+	if len(inits) == 0 {
+		return nil, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("gonfig: could not bootstrap: %w, got %q", ErrExpectPointer, rv.Kind())
+	}
+
+	phase := l.bootPhase
+	if phase == nil {
+		phase = defaultBootstrapPhase
+	}
+
+	var sequence []ParserType
+	for _, typ := range l.sequence {
+		if _, ok := l.groups[typ].(*bootstrapParser); !ok && slices.Contains(phase, typ) {
+			sequence = append(sequence, typ)
+		}
+	}
+
+	boot := reflect.New(rv.Type().Elem()).Interface()
+	if err := l.execute(ctx, l.newLayers(boot, nil), sequence); err != nil {
+		return nil, err
+	}
+
+	parsers := make(map[ParserType]Parser, len(inits))
+	for _, item := range inits {
+		parser, err := item.init(l.Config, boot)
+		if err != nil {
+			return nil, fmt.Errorf("gonfig: could not bootstrap parser %q: %w", item.name, err)
+		}
+
+		if parser != nil && parser.Type() != item.name {
+			return nil, fmt.Errorf("gonfig: could not bootstrap parser %q: unexpected type %q", item.name, parser.Type())
+		}
+
+		parsers[item.name] = parser
+	}
+
+	return parsers, nil
+}
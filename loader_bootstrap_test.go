@@ -0,0 +1,153 @@
+package gonfig_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/im-kulikov/gonfig"
+)
+
+type BootstrapConfig struct {
+	Consul struct {
+		Address string `env:"ADDRESS" flag:"consul-address" default:"localhost:8500"`
+		Token   string `env:"TOKEN"`
+	} `env:"CONSUL"`
+
+	Database string `env:"DATABASE" flag:"database" default:"default-database"`
+	Replicas int    `flag:"replicas"`
+}
+
+const parserConsulType gonfig.ParserType = "consul"
+
+func consulBootstrap(remote map[string]string) gonfig.BootstrapInit {
+	return func(_ gonfig.Config, boot any) (gonfig.Parser, error) {
+		cfg := boot.(*BootstrapConfig)
+		if cfg.Consul.Token == "" {
+			return nil, nil
+		}
+
+		value, ok := remote[cfg.Consul.Address]
+		if !ok {
+			return nil, errors.New("unknown consul address " + cfg.Consul.Address)
+		}
+
+		return gonfig.NewCustomParser(parserConsulType, func(dest any) error {
+			dest.(*BootstrapConfig).Database = value
+
+			return nil
+		}), nil
+	}
+}
+
+func TestBootstrapParser(t *testing.T) {
+	remote := map[string]string{"consul:8500": "consul-database"}
+	envs := []string{"CONSUL_ADDRESS=consul:8500", "CONSUL_TOKEN=token"}
+
+	t.Run("bootstrap values", func(t *testing.T) {
+		var cfg BootstrapConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: envs, Args: []string{"--replicas", "3"}},
+			gonfig.WithBootstrapParser(parserConsulType, consulBootstrap(remote))).Load(&cfg))
+
+		require.Equal(t, "consul-database", cfg.Database)
+		require.Equal(t, "consul:8500", cfg.Consul.Address)
+		require.Equal(t, 3, cfg.Replicas)
+	})
+
+	t.Run("flags override remote", func(t *testing.T) {
+		var cfg BootstrapConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: envs, Args: []string{"--database", "flag-database"}},
+			gonfig.WithBootstrapParser(parserConsulType, consulBootstrap(remote))).Load(&cfg))
+
+		require.Equal(t, "flag-database", cfg.Database)
+	})
+
+	t.Run("skipped by fabric", func(t *testing.T) {
+		var cfg BootstrapConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+			gonfig.WithBootstrapParser(parserConsulType, consulBootstrap(remote))).Load(&cfg))
+
+		require.Equal(t, "default-database", cfg.Database)
+	})
+
+	t.Run("bootstrap phase", func(t *testing.T) {
+		var cfg BootstrapConfig
+		err := gonfig.New(gonfig.Config{Envs: envs, Args: []string{"--consul-address", "other:8500"}},
+			gonfig.WithBootstrapParser(parserConsulType, consulBootstrap(remote))).Load(&cfg)
+		require.EqualError(t, err, `gonfig: could not bootstrap parser "consul": unknown consul address other:8500`)
+
+		cfg = BootstrapConfig{}
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: envs, Args: []string{"--consul-address", "other:8500"}},
+			gonfig.WithBootstrapPhase(gonfig.ParserDefaults, gonfig.ParserEnv),
+			gonfig.WithBootstrapParser(parserConsulType, consulBootstrap(remote))).Load(&cfg))
+
+		require.Equal(t, "consul-database", cfg.Database)
+		require.Equal(t, "other:8500", cfg.Consul.Address)
+	})
+
+	t.Run("order", func(t *testing.T) {
+		var cfg BootstrapConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: envs, Args: []string{"--database", "flag-database"}},
+			gonfig.WithBootstrapParser(parserConsulType, consulBootstrap(remote)),
+			gonfig.WithOrder(gonfig.ParserDefaults, gonfig.ParserEnv, gonfig.ParserFlags, parserConsulType)).Load(&cfg))
+
+		require.Equal(t, "consul-database", cfg.Database)
+	})
+}
+
+type optionalConsulParser struct{ err error }
+
+func (*optionalConsulParser) Type() gonfig.ParserType { return parserConsulType }
+
+func (p *optionalConsulParser) Load(any) error { return p.err }
+
+func (*optionalConsulParser) Optional() bool { return true }
+
+func TestBootstrapParser_Optional(t *testing.T) {
+	cases := map[string]gonfig.Parser{
+		"failed load": &optionalConsulParser{err: errors.New("consul is down")},
+		"failed decode": gonfig.NewMapParser(&mapConfigSource{mapSource: mapSource{
+			name: parserConsulType, optional: true, values: map[string]any{"replicas": "many"},
+		}}),
+	}
+
+	for name, parser := range cases {
+		t.Run(name, func(t *testing.T) {
+			var (
+				buf bytes.Buffer
+				cfg BootstrapConfig
+			)
+
+			require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+				gonfig.WithLogger(newTestLogger(&buf)),
+				gonfig.WithBootstrapParser(parserConsulType, func(gonfig.Config, any) (gonfig.Parser, error) {
+					return parser, nil
+				})).Load(&cfg))
+
+			require.Equal(t, "default-database", cfg.Database)
+			require.Contains(t, buf.String(), `level=WARN msg="gonfig: optional parser failed, skipped" parser=consul`)
+		})
+	}
+}
+
+func TestBootstrapParser_Errors(t *testing.T) {
+	_, err := gonfig.NewE(gonfig.Config{}, gonfig.WithBootstrapParser(parserConsulType, nil))
+	require.EqualError(t, err, `gonfig: could not init option: bootstrap parser "consul": empty fabric`)
+
+	parser := gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+		gonfig.WithBootstrapParser(parserConsulType, func(gonfig.Config, any) (gonfig.Parser, error) {
+			return gonfig.NewCustomParser("other", func(any) error { return nil }), nil
+		}))
+
+	require.EqualError(t, parser.Load(&BootstrapConfig{}),
+		`gonfig: could not bootstrap parser "consul": unexpected type "other"`)
+
+	require.EqualError(t, parser.Load(BootstrapConfig{}),
+		`gonfig: could not bootstrap: expect pointer, got "struct"`)
+
+	require.ErrorContains(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{"--unknown"}},
+		gonfig.WithBootstrapParser(parserConsulType, consulBootstrap(nil))).Load(&BootstrapConfig{}),
+		"unknown flag: --unknown")
+}
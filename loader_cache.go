@@ -0,0 +1,321 @@
+package gonfig
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// cacheFileMode defines permissions of the cache file, it can contain secrets,
+// so only the owner is allowed to read and write it.
+const cacheFileMode = 0o600
+
+// ErrCacheExpired is returned when the cached snapshot is older than the allowed CacheOptions.MaxAge.
+const ErrCacheExpired = constantError("cache expired")
+
+// CacheOptions holds the configuration of the last-known-good cache created by NewCachedParser.
+//
+// Fields:
+//
+//   - Path: The path to the cache file. The file is created with 0600 permissions.
+//
+//   - MaxAge: The maximum age of the snapshot that can be used as a fallback. If the snapshot
+//     is older, loading fails with ErrCacheExpired. Zero means that the snapshot never expires.
+//
+//   - Key: An optional AES key (16, 24 or 32 bytes long). If set, the snapshot is encrypted
+//     with AES-GCM before it is written to disk.
+//
+//   - Warn: An optional callback that is called when the live source fails and the snapshot
+//     is used instead. By default, the warning is logged with Logger.
+//
+//   - Logger: An optional logger for fallback decisions. If it is nil, the logger of the loader is used
+//     (see WithLogger), and nothing is logged when the parser is used on its own.
+type CacheOptions struct {
+	Path   string
+	MaxAge time.Duration
+	Key    []byte
+	Warn   func(error)
+	Logger *slog.Logger
+}
+
+// cachedParser wraps a Parser and persists values of the last successful load of the wrapped parser,
+// which are used as a fallback when the live source fails.
+type cachedParser struct {
+	Parser
+	CacheOptions
+}
+
+// cacheSnapshot is the on-disk representation of values of the last successful load of the wrapped
+// parser: JSON-encoded values of fields it set, by paths of the fields (see ReflectValue.Path).
+type cacheSnapshot struct {
+	SavedAt time.Time                  `json:"saved_at"`
+	Fields  map[string]json.RawMessage `json:"fields"`
+}
+
+// NewCachedParser creates an opt-in last-known-good cache around any Parser, which is mostly
+// useful for remote sources like Consul or Vault that can be unavailable at boot.
+//
+// The wrapped parser is loaded into a copy of the destination, and only the fields it changed
+// (including the ones it set to zero values) are merged with values of other sources, like the layer
+// of a MapSource. After every successful load these fields are encoded as JSON (values of secret fields
+// included, see Secret) and persisted to CacheOptions.Path, if the snapshot can not be written, a warning
+// is logged and the live values are still applied. When the wrapped parser fails, the persisted fields
+// are merged instead and a warning is reported, so values of other sources of the current load are kept.
+// If there is no snapshot, it can not be read or it is older than CacheOptions.MaxAge, the original
+// error is returned together with the cache error.
+//
+// The wrapped parser keeps its type, the config path is passed through when it implements
+// the ParserConfigSetter interface, and it stays optional when it implements ParserOptional.
+//
+// Example usage:
+//
+//	parser := gonfig.NewCachedParser(consulParser, gonfig.CacheOptions{
+//	    Path:   "/var/cache/app/consul.json",
+//	    MaxAge: 24 * time.Hour,
+//	})
+func NewCachedParser(p Parser, options CacheOptions) Parser {
+	return &cachedParser{Parser: p, CacheOptions: options}
+}
+
+// SetConfigPath passes the config path to the wrapped parser if it implements ParserConfigSetter.
+func (c *cachedParser) SetConfigPath(path string) {
+	if setter, ok := c.Parser.(ParserConfigSetter); ok {
+		setter.SetConfigPath(path)
+	}
+}
+
+// Optional reports whether the wrapped parser is optional, see ParserOptional.
+func (c *cachedParser) Optional() bool {
+	optional, ok := c.Parser.(ParserOptional)
+
+	return ok && optional.Optional()
+}
+
+// Load loads the configuration using the wrapped parser and persists it, or falls back to the
+// last persisted snapshot if the wrapped parser fails.
+func (c *cachedParser) Load(dest interface{}) error {
+	return c.LoadContext(context.Background(), dest)
+}
+
+// LoadContext works like Load, but passes the context to the wrapped parser if it implements
+// the ContextParser interface. A cancelled or timed out load falls back to the snapshot as well.
+func (c *cachedParser) LoadContext(ctx context.Context, dest interface{}) error {
+	// the wrapped parser is loaded into a copy, so the snapshot holds only the values it changed.
+	before, scratch := snapshot(dest), snapshot(dest)
+	if scratch == nil {
+		scratch = dest
+	}
+
+	logger := c.Logger
+	if logger == nil {
+		logger = loggerOf(ctx)
+	}
+
+	err := loadContext(withoutLayers(ctx), c.Parser, scratch)
+	if err == nil {
+		tree, fields, err := cacheTree(before, scratch)
+		if err != nil {
+			return fmt.Errorf("(cache) %w", err)
+		}
+
+		// live values are applied anyway, only the next fallback would miss them.
+		if err = c.save(fields); err != nil {
+			logger.LogAttrs(ctx, slog.LevelWarn, "gonfig: could not save cached config",
+				slog.String("parser", string(c.Type())), slog.String("path", c.Path), slog.Any("error", err))
+		}
+
+		return contribute(ctx, layer{source: c.Type(), tree: tree}, dest)
+	}
+
+	tree, cacheErr := c.restore(dest)
+	if cacheErr != nil {
+		logger.LogAttrs(ctx, slog.LevelError, "gonfig: could not fall back to cached config",
+			slog.String("parser", string(c.Type())), slog.String("path", c.Path), slog.Any("error", cacheErr))
+
+		return errors.Join(err, fmt.Errorf("(cache) could not restore snapshot: %w", cacheErr))
+	}
+
+	warn := fmt.Errorf("(cache) %q failed, last known config from %q is used: %w", c.Type(), c.Path, err)
+	if c.Warn != nil {
+		c.Warn(warn)
+	} else {
+		logger.LogAttrs(ctx, slog.LevelWarn, "gonfig: fall back to cached config",
+			slog.String("parser", string(c.Type())), slog.String("path", c.Path), slog.Any("error", err))
+	}
+
+	return contribute(ctx, layer{source: c.Type(), tree: tree}, dest)
+}
+
+// needsDest reports true, the wrapped parser is loaded into a copy of the destination with values
+// of the previous sources, so fields it changed can be told apart from the ones it did not set.
+func (c *cachedParser) needsDest() bool { return true }
+
+// cacheTree returns the key tree of fields changed by the wrapped parser (fields of the loaded copy
+// that differ from the destination before the load, see Diff) and their JSON-encoded values.
+// Secret fields are encoded with their values, not redacted.
+func cacheTree(before, loaded any) (keyTree, map[string]json.RawMessage, error) {
+	changed := make(map[string]bool)
+	for _, change := range Diff(before, loaded) {
+		changed[change.Path] = true
+	}
+
+	tree := make(keyTree)
+	fields := make(map[string]json.RawMessage)
+	for elem, err := range ReflectFieldsOf(loaded, treeOptions) {
+		if err != nil {
+			return nil, nil, err
+		} else if !changed[elem.Path()] {
+			continue
+		}
+
+		value := secretOf(elem.Value).Interface()
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not encode field %q: %w", elem.Path(), err)
+		}
+
+		tree[elem.Path()] = treeValue{value: value, raw: rawString(elem, value)}
+		fields[elem.Path()] = data
+	}
+
+	return tree, fields, nil
+}
+
+// save writes fields into the snapshot and atomically writes it to the cache file.
+func (c *cachedParser) save(fields map[string]json.RawMessage) error {
+	data, err := json.Marshal(cacheSnapshot{SavedAt: time.Now(), Fields: fields})
+	if err != nil {
+		return err
+	}
+
+	if data, err = c.encrypt(data); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.Path), filepath.Base(c.Path)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err = tmp.Chmod(cacheFileMode); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), c.Path)
+}
+
+// restore reads the snapshot from the cache file, checks its age and decodes persisted fields into
+// the key tree of the destination, keys of values are the path to the cache file.
+func (c *cachedParser) restore(dest any) (keyTree, error) {
+	data, err := os.ReadFile(c.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err = c.decrypt(data); err != nil {
+		return nil, err
+	}
+
+	var snapshot cacheSnapshot
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	} else if snapshot.Fields == nil {
+		return nil, fmt.Errorf("snapshot has no fields, it was saved in an unsupported format")
+	}
+
+	if age := time.Since(snapshot.SavedAt); c.MaxAge > 0 && age > c.MaxAge {
+		return nil, fmt.Errorf("%w: saved %s ago, max age is %s", ErrCacheExpired, age.Round(time.Second), c.MaxAge)
+	}
+
+	tree := make(keyTree)
+	for elem, err := range ReflectFieldsOf(dest, treeOptions) {
+		if err != nil {
+			return nil, err
+		}
+
+		data, ok := snapshot.Fields[elem.Path()]
+		if !ok {
+			continue
+		}
+
+		value := reflect.New(secretOf(elem.Value).Type())
+		if err = json.Unmarshal(data, value.Interface()); err != nil {
+			return nil, fmt.Errorf("could not decode field %q: %w", elem.Path(), redactError(elem, err))
+		}
+
+		tree[elem.Path()] = treeValue{value: value.Elem().Interface(), key: c.Path, raw: rawString(elem, value.Elem().Interface())}
+	}
+
+	return tree, nil
+}
+
+// encrypt seals the data with AES-GCM if the key is provided, the nonce is prepended to the result.
+func (c *cachedParser) encrypt(data []byte) ([]byte, error) {
+	if len(c.Key) == 0 {
+		return data, nil
+	}
+
+	aead, err := c.cipher()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// decrypt opens the data sealed by encrypt if the key is provided.
+func (c *cachedParser) decrypt(data []byte) ([]byte, error) {
+	if len(c.Key) == 0 {
+		return data, nil
+	}
+
+	aead, err := c.cipher()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted snapshot is too short")
+	}
+
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+}
+
+// cipher creates AES-GCM cipher based on the provided key.
+func (c *cachedParser) cipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(c.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
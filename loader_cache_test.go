@@ -0,0 +1,272 @@
+package gonfig_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/im-kulikov/gonfig"
+)
+
+type CachedConfig struct {
+	Address string `json:"address"`
+	Token   string `json:"token"`
+}
+
+func TestCachedParser(t *testing.T) {
+	for name, key := range map[string][]byte{
+		"plain":     nil,
+		"encrypted": []byte("0123456789abcdef0123456789abcdef"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var (
+				failed = errors.New("consul is down")
+				remote = &CachedConfig{Address: "consul:8500", Token: "secret-token"}
+				warns  []error
+			)
+
+			path := filepath.Join(t.TempDir(), "cache.json")
+			parser := gonfig.NewCachedParser(gonfig.NewCustomParser("consul", func(dest any) error {
+				if remote == nil {
+					return failed
+				}
+
+				*dest.(*CachedConfig) = *remote
+
+				return nil
+			}), gonfig.CacheOptions{Path: path, Key: key, Warn: func(err error) { warns = append(warns, err) }})
+
+			require.Equal(t, gonfig.ParserType("consul"), parser.Type())
+
+			var cfg CachedConfig
+			require.NoError(t, parser.Load(&cfg))
+			require.Equal(t, *remote, cfg)
+			require.Empty(t, warns)
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, key == nil, strings.Contains(string(data), "secret-token"))
+
+			remote = nil
+
+			var fallback CachedConfig
+			require.NoError(t, parser.Load(&fallback))
+			require.Equal(t, cfg, fallback)
+			require.Len(t, warns, 1)
+			require.ErrorIs(t, warns[0], failed)
+		})
+	}
+}
+
+func TestCachedParser_Errors(t *testing.T) {
+	var buf bytes.Buffer
+
+	failed := errors.New("vault is down")
+	broken := gonfig.NewCustomParser("vault", func(any) error { return failed })
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	t.Run("no snapshot", func(t *testing.T) {
+		parser := gonfig.NewCachedParser(broken, gonfig.CacheOptions{
+			Path:   filepath.Join(t.TempDir(), "cache.json"),
+			Logger: logger,
+		})
+
+		err := parser.Load(&CachedConfig{})
+		require.ErrorIs(t, err, failed)
+		require.ErrorIs(t, err, os.ErrNotExist)
+		require.Contains(t, buf.String(), `level=ERROR msg="gonfig: could not fall back to cached config" parser=vault`)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cache.json")
+		data, err := json.Marshal(map[string]any{
+			"saved_at": time.Now().Add(-time.Hour),
+			"fields":   map[string]any{"Address": "stale"},
+		})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		parser := gonfig.NewCachedParser(broken, gonfig.CacheOptions{Path: path, MaxAge: time.Minute, Logger: logger})
+
+		var cfg CachedConfig
+		err = parser.Load(&cfg)
+		require.ErrorIs(t, err, failed)
+		require.ErrorIs(t, err, gonfig.ErrCacheExpired)
+		require.Empty(t, cfg.Address)
+
+		buf.Reset()
+
+		parser = gonfig.NewCachedParser(broken, gonfig.CacheOptions{Path: path, Logger: logger})
+		require.NoError(t, parser.Load(&cfg))
+		require.Equal(t, "stale", cfg.Address)
+		require.Contains(t, buf.String(), `level=WARN msg="gonfig: fall back to cached config" parser=vault`)
+	})
+
+	t.Run("logger of the loader", func(t *testing.T) {
+		var (
+			buf bytes.Buffer
+			cfg CachedConfig
+		)
+
+		path := filepath.Join(t.TempDir(), "cache.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"saved_at":"2024-01-01T00:00:00Z","fields":{"Address":"cached"}}`), 0o600))
+
+		parser := gonfig.NewCachedParser(broken, gonfig.CacheOptions{Path: path})
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+			gonfig.WithCustomParser(parser), gonfig.WithLogger(newTestLogger(&buf))).Load(&cfg))
+
+		require.Equal(t, "cached", cfg.Address)
+		require.Contains(t, buf.String(), `level=WARN msg="gonfig: fall back to cached config" parser=vault`)
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cache.json")
+		key := []byte("0123456789abcdef")
+
+		ok := gonfig.NewCustomParser("vault", func(any) error { return nil })
+		require.NoError(t, gonfig.NewCachedParser(ok, gonfig.CacheOptions{Path: path, Key: key}).Load(&CachedConfig{}))
+
+		parser := gonfig.NewCachedParser(broken, gonfig.CacheOptions{Path: path, Key: []byte("fedcba9876543210"), Logger: logger})
+		require.ErrorIs(t, parser.Load(&CachedConfig{}), failed)
+
+		parser = gonfig.NewCachedParser(broken, gonfig.CacheOptions{Path: path, Key: []byte("short"), Logger: logger})
+		require.ErrorIs(t, parser.Load(&CachedConfig{}), failed)
+	})
+
+	t.Run("unwritable", func(t *testing.T) {
+		buf.Reset()
+
+		ok := gonfig.NewCustomParser("vault", func(dest any) error {
+			dest.(*CachedConfig).Address = "vault:8200"
+
+			return nil
+		})
+
+		var cfg CachedConfig
+		parser := gonfig.NewCachedParser(ok, gonfig.CacheOptions{Path: filepath.Join(t.TempDir(), "missing", "cache.json"), Logger: logger})
+		require.NoError(t, parser.Load(&cfg))
+		require.Equal(t, "vault:8200", cfg.Address, "live values are applied")
+		require.Contains(t, buf.String(), `level=WARN msg="gonfig: could not save cached config" parser=vault`)
+	})
+}
+
+func TestCachedParser_ConfigPath(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "config.json")
+	require.NoError(t, err)
+	require.NoError(t, json.NewEncoder(file).Encode(CustomLoaderConfig{FieldString: "json-value"}))
+	require.NoError(t, file.Close())
+
+	var cfg CustomLoaderConfig
+	require.NoError(t, gonfig.New(gonfig.Config{Args: []string{"--config", file.Name()}},
+		gonfig.WithCustomParser(gonfig.NewCachedParser(&customJSONParser{}, gonfig.CacheOptions{
+			Path: filepath.Join(t.TempDir(), "cache.json"),
+		}))).Load(&cfg))
+
+	require.Equal(t, "json-value", cfg.FieldString)
+}
+
+type optionalCachedParser struct {
+	gonfig.Parser
+}
+
+func (optionalCachedParser) Optional() bool { return true }
+
+func TestCachedParser_Merge(t *testing.T) {
+	type Config struct {
+		Port     int                   `env:"PORT" default:"80"`
+		Address  string                `env:"ADDRESS"`
+		Password gonfig.Secret[string] `env:"PASSWORD"`
+	}
+
+	var (
+		failed = errors.New("consul is down")
+		up     = true
+	)
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+	parser := gonfig.NewCachedParser(gonfig.NewCustomParser("consul", func(dest any) error {
+		if !up {
+			return failed
+		}
+
+		dest.(*Config).Address = "consul:8500"
+		dest.(*Config).Password = gonfig.NewSecret("p@ss")
+
+		return nil
+	}), gonfig.CacheOptions{Path: path, Warn: func(error) {}})
+
+	var cfg Config
+	require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{"PORT=1"}, Args: []string{}},
+		gonfig.WithCustomParser(parser)).Load(&cfg))
+	require.Equal(t, Config{Port: 1, Address: "consul:8500", Password: gonfig.NewSecret("p@ss")}, cfg)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "Port", "only values of the wrapped parser are cached")
+	require.NotContains(t, string(data), gonfig.RedactedValue, "values of secrets are cached as is")
+
+	up = false
+
+	cfg = Config{}
+	loader := gonfig.New(gonfig.Config{Envs: []string{"PORT=2"}, Args: []string{}}, gonfig.WithCustomParser(parser))
+	sources, err := loader.LoadExplain(context.Background(), &cfg)
+	require.NoError(t, err)
+	require.Equal(t, Config{Port: 2, Address: "consul:8500", Password: gonfig.NewSecret("p@ss")}, cfg,
+		"values of other sources are kept, secrets are restored")
+	require.Contains(t, sources,
+		gonfig.FieldSource{Path: "Address", Source: "consul", Key: path, Raw: "consul:8500"})
+
+	t.Run("zero values", func(t *testing.T) {
+		type Config struct {
+			Enabled bool `default:"true"`
+		}
+
+		up := true
+		path := filepath.Join(t.TempDir(), "cache.json")
+		parser := gonfig.NewCachedParser(gonfig.NewCustomParser("consul", func(dest any) error {
+			if !up {
+				return failed
+			}
+
+			dest.(*Config).Enabled = false
+
+			return nil
+		}), gonfig.CacheOptions{Path: path, Warn: func(error) {}})
+
+		var cfg Config
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+			gonfig.WithCustomParser(parser)).Load(&cfg))
+		require.False(t, cfg.Enabled, "zero values set by the wrapped parser are applied")
+
+		up = false
+
+		cfg = Config{}
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+			gonfig.WithCustomParser(parser)).Load(&cfg))
+		require.False(t, cfg.Enabled, "zero values are restored from the snapshot")
+	})
+
+	t.Run("optional", func(t *testing.T) {
+		broken := gonfig.NewCachedParser(optionalCachedParser{gonfig.NewCustomParser("vault", func(any) error { return failed })},
+			gonfig.CacheOptions{Path: filepath.Join(t.TempDir(), "cache.json"), Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+		require.Implements(t, (*gonfig.ParserOptional)(nil), broken)
+
+		var cfg Config
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}}, gonfig.WithCustomParser(broken)).Load(&cfg))
+		require.Equal(t, 80, cfg.Port)
+	})
+}
@@ -0,0 +1,150 @@
+package gonfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	FlagPrintConfig = "print-config" // FlagPrintConfig is the flag that prints the effective configuration, see WithConfigCommands.
+	FlagCheckConfig = "check-config" // FlagCheckConfig is the flag that validates the configuration, see WithConfigCommands.
+)
+
+// configFormats are formats supported by the FlagPrintConfig.
+var configFormats = []string{FormatJSON, FormatYAML, FormatTOML, FormatEnv}
+
+// configCommands holds commands requested by command-line arguments.
+type configCommands struct {
+	print string // format of the printed configuration, empty if it is not requested.
+	check bool
+}
+
+// WithConfigCommands creates a LoaderOption that enables built-in flags for operators, which let them
+// inspect the configuration without starting the service:
+//
+//   - `--print-config[=json|yaml|toml|env]` runs the whole chain of parsers, prints the effective configuration
+//     with secrets redacted (see SecretTag) and sources of values (see Loader.LoadExplain), and exits.
+//     YAML is used when the format is omitted, see Export for details of formats.
+//   - `--check-config` runs the whole chain of parsers and ValidateRequiredFields, reports all errors
+//     and exits with code 1, or exits with code 0 if the configuration is valid.
+//
+// The configuration is printed to `Config.Output` (or os.Stdout), errors of the check are printed to
+// `Config.Output` (or os.Stderr). The process is terminated with os.Exit, unless it is replaced by
+// WithCustomExit: if the custom function returns, Load returns nil or the error of the check.
+//
+// Both flags are shown in the usage (see ErrHelp). Commands are parsed by the built-in flags parser,
+// so they can not be enabled together with `Config.SkipFlags`.
+func WithConfigCommands() LoaderOption {
+	return func(l *loader) error {
+		flags, ok := l.groups[ParserFlags].(*flagsParser)
+		if !ok {
+			return fmt.Errorf("config commands require the built-in flags parser")
+		}
+
+		flags.commands = true
+		l.commands = true
+
+		return nil
+	}
+}
+
+// defineCommands adds flags of commands to the flag set, flags that are already defined by
+// the destination are kept as is.
+func defineCommands(set *pflag.FlagSet, commands *configCommands) {
+	if set.Lookup(FlagPrintConfig) == nil {
+		set.StringVar(&commands.print, FlagPrintConfig, "", "print the effective configuration (json, yaml, toml or env) and exit")
+		set.Lookup(FlagPrintConfig).NoOptDefVal = FormatYAML
+	}
+
+	if set.Lookup(FlagCheckConfig) == nil {
+		set.BoolVar(&commands.check, FlagCheckConfig, false, "check the configuration and exit")
+	}
+}
+
+// parseCommands resolves commands requested by the command-line arguments, other flags are ignored.
+func parseCommands(args []string) (configCommands, error) {
+	flags := pflag.NewFlagSet("commands", pflag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
+
+	var commands configCommands
+	defineCommands(flags, &commands)
+
+	if err := flags.Parse(args); err != nil && !errors.Is(err, pflag.ErrHelp) {
+		return commands, fmt.Errorf("(commands) could not parse flags: %w", err)
+	}
+
+	if commands.print != "" && !slices.Contains(configFormats, commands.print) {
+		return commands, fmt.Errorf("(commands) unknown format %q of --%s, expect one of %q",
+			commands.print, FlagPrintConfig, configFormats)
+	}
+
+	return commands, nil
+}
+
+// printConfig prints the effective configuration of the destination and terminates the process.
+func (l *loader) printConfig(v any, format string, sources []FieldSource) error {
+	output := l.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	if err := writeConfig(output, v, format, exportOptions{
+		prefix:       l.envPrefixes()[0],
+		sources:      sources,
+		autoEnv:      l.AutoEnv,
+		envDelimiter: l.envNesting(),
+	}); err != nil {
+		return fmt.Errorf("gonfig: could not print config: %w", err)
+	}
+
+	return l.terminate(0, nil)
+}
+
+// checkConfig reports errors of the loaded configuration and terminates the process,
+// with code 1 if there are errors and with code 0 otherwise.
+func (l *loader) checkConfig(v any, err error) error {
+	errs := []error{err}
+	if err == nil || !errors.Is(err, ErrExpectPointer) && !errors.Is(err, ErrExpectStruct) {
+		errs = append(errs, ValidateRequiredFields(v))
+	}
+
+	if err = errors.Join(errs...); err == nil {
+		output := l.Output
+		if output == nil {
+			output = os.Stdout
+		}
+
+		_, _ = io.WriteString(output, "gonfig: configuration is valid\n")
+
+		return l.terminate(0, nil)
+	}
+
+	output := l.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	_, _ = fmt.Fprintf(output, "gonfig: configuration is invalid:\n%s\n", err)
+
+	return l.terminate(1, err)
+}
+
+// terminate exits the process with the provided code. If os.Exit is replaced by WithCustomExit
+// and the custom function returns, the provided error is returned.
+func (l *loader) terminate(code int, err error) error {
+	if l.exit != nil {
+		l.exit(code)
+
+		return err
+	}
+
+	os.Exit(code)
+
+	return err
+}
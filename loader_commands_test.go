@@ -0,0 +1,171 @@
+package gonfig_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/im-kulikov/gonfig"
+)
+
+type CommandsConfig struct {
+	Address  string        `json:"address" env:"ADDRESS" flag:"address" default:":8080"`
+	Password string        `json:"password" env:"PASSWORD" secret:"true"`
+	Timeout  time.Duration `json:"timeout" env:"TIMEOUT" default:"5s"`
+	Hosts    []string      `json:"hosts" env:"HOSTS"`
+	Token    string        `json:"token" env:"TOKEN" required:"true"`
+
+	Database struct {
+		Host string `json:"host" env:"HOST"`
+	} `json:"db" env:"DB"`
+}
+
+func TestWithConfigCommands(t *testing.T) {
+	config := gonfig.Config{
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_PASSWORD=secret", "APP_HOSTS=a,b", "APP_DB_HOST=db"},
+	}
+
+	load := func(t *testing.T, args ...string) (string, int, error) {
+		t.Helper()
+
+		var (
+			buf  bytes.Buffer
+			code = -1
+		)
+
+		config := config
+		config.Args = args
+		config.Output = &buf
+
+		err := gonfig.New(config,
+			gonfig.WithConfigCommands(),
+			gonfig.WithCustomExit(func(c int) { code = c })).Load(&CommandsConfig{})
+
+		return buf.String(), code, err
+	}
+
+	t.Run("print yaml", func(t *testing.T) {
+		out, code, err := load(t, "--address", ":9090", "--print-config")
+		require.NoError(t, err, "required fields are not validated")
+		require.Equal(t, 0, code)
+		require.Equal(t, `address: :9090 # flags --address
+password: '******' # env APP_PASSWORD
+timeout: 5s # defaults
+hosts: # env APP_HOSTS
+  - a
+  - b
+token: ""
+db:
+  host: db # env APP_DB_HOST
+`, out)
+	})
+
+	t.Run("print json", func(t *testing.T) {
+		out, code, err := load(t, "--print-config=json")
+		require.NoError(t, err)
+		require.Equal(t, 0, code)
+		require.JSONEq(t, `{
+			"config": {
+				"address": ":8080",
+				"password": "******",
+				"timeout": "5s",
+				"hosts": ["a", "b"],
+				"token": "",
+				"db": {"host": "db"}
+			},
+			"sources": {
+				"Address": "defaults",
+				"Password": "env APP_PASSWORD",
+				"Timeout": "defaults",
+				"Hosts": "env APP_HOSTS",
+				"Database.Host": "env APP_DB_HOST"
+			}
+		}`, out)
+	})
+
+	t.Run("print env", func(t *testing.T) {
+		out, code, err := load(t, "--print-config=env")
+		require.NoError(t, err)
+		require.Equal(t, 0, code)
+		require.Equal(t, `# defaults
+APP_ADDRESS=:8080
+# env APP_PASSWORD
+APP_PASSWORD=******
+# defaults
+APP_TIMEOUT=5s
+# env APP_HOSTS
+APP_HOSTS=a,b
+APP_TOKEN=
+# env APP_DB_HOST
+APP_DB_HOST=db
+`, out)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, code, err := load(t, "--print-config=xml")
+		require.EqualError(t, err, `gonfig: could not load: (commands) unknown format "xml" of --print-config, expect one of ["json" "yaml" "toml" "env"]`)
+		require.Equal(t, -1, code)
+	})
+
+	t.Run("check valid", func(t *testing.T) {
+		config := config
+		config.Envs = append(config.Envs, "APP_TOKEN=token")
+		config.Args = []string{"--check-config"}
+
+		var (
+			buf  bytes.Buffer
+			code = -1
+		)
+
+		config.Output = &buf
+		require.NoError(t, gonfig.New(config,
+			gonfig.WithConfigCommands(),
+			gonfig.WithCustomExit(func(c int) { code = c })).Load(&CommandsConfig{}))
+		require.Equal(t, 0, code)
+		require.Equal(t, "gonfig: configuration is valid\n", buf.String())
+	})
+
+	t.Run("check invalid", func(t *testing.T) {
+		config := config
+		config.Envs = append(config.Envs, "APP_TIMEOUT=forever")
+
+		var (
+			buf  bytes.Buffer
+			code = -1
+		)
+
+		config.Args = []string{"--check-config"}
+		config.Output = &buf
+		err := gonfig.New(config,
+			gonfig.WithConfigCommands(),
+			gonfig.WithCustomExit(func(c int) { code = c })).Load(&CommandsConfig{})
+		require.Error(t, err)
+		require.Equal(t, 1, code)
+		require.Contains(t, err.Error(), `could not decode field "Timeout"`)
+		require.Contains(t, err.Error(), "field `Token` <string> is required")
+		require.Equal(t, "gonfig: configuration is invalid:\n"+err.Error()+"\n", buf.String())
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		out, code, err := load(t, "--help")
+		require.NoError(t, err)
+		require.Equal(t, 0, code)
+		require.Contains(t, out, "--print-config string[=\"yaml\"]")
+		require.Contains(t, out, "--check-config")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		config := config
+		config.Args = []string{"--print-config"}
+		require.EqualError(t, gonfig.New(config).Load(&CommandsConfig{}),
+			"gonfig: could not load: unknown flag: --print-config")
+	})
+
+	t.Run("skip flags", func(t *testing.T) {
+		_, err := gonfig.NewE(gonfig.Config{SkipFlags: true}, gonfig.WithConfigCommands())
+		require.EqualError(t, err, "gonfig: could not init option: config commands require the built-in flags parser")
+	})
+}
@@ -1,5 +1,11 @@
 package gonfig
 
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
 // Parser interface represents an abstraction for loading configuration.
 // Implementations of this interface are responsible for loading configuration data
 // from various sources into a specified destination object.
@@ -16,6 +22,26 @@ type Parser interface {
 	Type() ParserType
 }
 
+// ContextParser is an optional interface for parsers that support deadlines and cancellation,
+// which is mostly useful for remote sources. When a parser implements it, the loader prefers
+// LoadContext over Load and passes the context of the current load (limited by the per-parser
+// timeout, if any, see WithParserTimeout).
+type ContextParser interface {
+	Parser
+
+	// LoadContext loads the configuration into the specified destination object.
+	// It behaves like Load, but must stop and return the context error when the context is done.
+	LoadContext(ctx context.Context, dest interface{}) error
+}
+
+// ParserOptional is an optional interface for parsers whose failures should not abort loading.
+// When Optional reports true, the loader logs the error of the parser as a warning and continues
+// with the next parser, see WithOptionalParser.
+type ParserOptional interface {
+	// Optional reports whether the parser is optional.
+	Optional() bool
+}
+
 // ParserConfigSetter defines an interface for setting the configuration file path.
 // Implementing types are expected to provide a method to set the path where
 // the configuration file for the parser is located.
@@ -34,6 +60,13 @@ type parserFunc struct {
 	call func(interface{}) error
 }
 
+// contextParserFunc is a concrete implementation of the ContextParser interface.
+// It wraps a context-aware function that performs the actual loading of configuration data.
+type contextParserFunc struct {
+	name ParserType
+	call func(context.Context, interface{}) error
+}
+
 // ParserInit is a function type that allows initializing a Parser with the provided loader Config.
 // It takes a Config object as an argument and returns a Parser along with any initialization error.
 // This function is used to create custom parsers based on the configuration settings.
@@ -71,3 +104,141 @@ func (p *parserFunc) Load(dest interface{}) error {
 func NewCustomParser(name ParserType, Loader func(interface{}) error) Parser {
 	return &parserFunc{name: name, call: Loader}
 }
+
+// NewCustomContextParser creates a new custom parser with the specified name and context-aware loader function.
+// It works like NewCustomParser, but the returned parser implements the ContextParser interface, so the
+// loader function receives the context of the current load and can respect its deadline and cancellation.
+//
+// Example usage:
+//
+//	remoteParser := NewCustomContextParser("remote", func(ctx context.Context, cfg interface{}) error {
+//	    req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://config/app.json", nil)
+//	    // ...
+//	})
+func NewCustomContextParser(name ParserType, loader func(context.Context, interface{}) error) ContextParser {
+	return &contextParserFunc{name: name, call: loader}
+}
+
+// Type returns the type of the current parser.
+func (p *contextParserFunc) Type() ParserType { return p.name }
+
+// Load invokes the function associated with the parser with the background context.
+func (p *contextParserFunc) Load(dest interface{}) error {
+	return p.call(context.Background(), dest)
+}
+
+// LoadContext invokes the function associated with the parser with the provided context.
+func (p *contextParserFunc) LoadContext(ctx context.Context, dest interface{}) error {
+	return p.call(ctx, dest)
+}
+
+// loadContext loads the configuration into the destination using LoadContext if the parser
+// implements the ContextParser interface. Otherwise, the parser can not be interrupted, so
+// the context is only checked before the call of Load.
+func loadContext(ctx context.Context, p Parser, dest interface{}) error {
+	if parser, ok := p.(ContextParser); ok {
+		return parser.LoadContext(ctx, dest)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return p.Load(dest)
+}
+
+// MapSource is an interface for sources that provide raw configuration values, e.g. decoded JSON or
+// YAML documents or key-value pairs of a remote store, instead of decoding them into the destination.
+// Values are merged with values of other sources by precedence and decoded into the destination once,
+// with the same decode hooks as environment variables (durations, IPs, comma-separated slices, etc.),
+// so the source does not need to re-implement decoding.
+//
+// Keys of the map are matched against the `json` tag names of fields (or their Go names, when the tag
+// is not set), exactly and then case-insensitively. Nested structs are nested maps, embedded structs
+// without the tag are squashed. Unknown keys are ignored, nil values reset fields to their zero values.
+//
+// A MapSource can also implement ParserConfigSetter and ParserOptional, see NewMapParser.
+type MapSource interface {
+	// Type returns the type of the source, it is used as the type of the parser.
+	Type() ParserType
+
+	// LoadMap returns raw configuration values of the source.
+	LoadMap(ctx context.Context) (map[string]any, error)
+}
+
+// mapSourceTag is the struct tag used to match keys of a MapSource.
+const mapSourceTag = "json"
+
+// mapParser adapts MapSource to the ContextParser interface.
+type mapParser struct {
+	source MapSource
+}
+
+// mapConfigParser is a mapParser of the source that implements ParserConfigSetter.
+type mapConfigParser struct {
+	*mapParser
+}
+
+// NewMapParser creates a parser from the provided MapSource. When the source implements
+// ParserConfigSetter or ParserOptional, the parser implements them as well, so it receives the
+// config path and its failures can be skipped. Used on its own, the parser decodes values of
+// the source into the destination, like any other parser.
+//
+// Example usage:
+//
+//	gonfig.New(gonfig.Config{}, gonfig.WithCustomParser(gonfig.NewMapParser(consulSource)))
+func NewMapParser(source MapSource) ContextParser {
+	parser := &mapParser{source: source}
+	if _, ok := source.(ParserConfigSetter); ok {
+		return &mapConfigParser{mapParser: parser}
+	}
+
+	return parser
+}
+
+// Type returns the type of the source.
+func (p *mapParser) Type() ParserType { return p.source.Type() }
+
+// Load loads values of the source with the background context.
+func (p *mapParser) Load(dest interface{}) error {
+	return p.LoadContext(context.Background(), dest)
+}
+
+// LoadContext loads values of the source and contributes them to the layers of the current load.
+func (p *mapParser) LoadContext(ctx context.Context, dest interface{}) error {
+	raw, err := p.source.LoadMap(ctx)
+	if err != nil {
+		return err
+	}
+
+	tree, err := rawTree(raw, dest, tagNames(mapSourceTag), func(keys []string) string {
+		key := strings.Join(keys, ".")
+		if locator, ok := p.source.(SourceLocator); ok {
+			if location := locator.Locate(key); location != "" {
+				return location
+			}
+		}
+
+		return key
+	})
+	if err != nil {
+		return fmt.Errorf("(%s) %w", p.Type(), err)
+	}
+
+	return contribute(ctx, layer{source: p.Type(), tag: mapSourceTag, tree: tree}, dest)
+}
+
+// Optional reports whether the source is optional, see ParserOptional.
+func (p *mapParser) Optional() bool {
+	optional, ok := p.source.(ParserOptional)
+
+	return ok && optional.Optional()
+}
+
+// needsDest reports false, values of the source do not depend on the destination.
+func (p *mapParser) needsDest() bool { return false }
+
+// SetConfigPath sets the config path to the source.
+func (p *mapConfigParser) SetConfigPath(path string) {
+	p.source.(ParserConfigSetter).SetConfigPath(path)
+}
@@ -1,9 +1,16 @@
 package gonfig_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -104,3 +111,179 @@ func TestCustomErrors(t *testing.T) {
 			"gonfig: could not load: (flags) shorthand is more than one ASCII character \"ff\"")
 	}
 }
+
+type optionalJSONParser struct {
+	customJSONParser
+}
+
+func (*optionalJSONParser) Optional() bool { return true }
+
+func TestOptionalParser(t *testing.T) {
+	args := []string{"--config", filepath.Join(t.TempDir(), "missing.json"), "--int-field", "8"}
+
+	t.Run("required parser fails", func(t *testing.T) {
+		err := gonfig.New(gonfig.Config{Args: args, Envs: []string{}},
+			gonfig.WithCustomParser(&customJSONParser{})).Load(&CustomLoaderConfig{})
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	cases := map[string]gonfig.LoaderOption{
+		"with option":    gonfig.WithOptionalParser(&customJSONParser{}),
+		"with interface": gonfig.WithCustomParser(&optionalJSONParser{}),
+	}
+
+	for name, option := range cases {
+		t.Run(name, func(t *testing.T) {
+			var (
+				buf bytes.Buffer
+				cfg CustomLoaderConfig
+			)
+
+			require.NoError(t, gonfig.New(gonfig.Config{Args: args, Envs: []string{}},
+				gonfig.WithOptionalParser(nil), option,
+				gonfig.WithLogger(slog.New(slog.NewTextHandler(&buf, nil)))).Load(&cfg))
+
+			require.Equal(t, "default-value", cfg.FieldString)
+			require.Equal(t, 8, cfg.FieldInt, "parsers after the optional one should be executed")
+			require.Contains(t, buf.String(), `level=WARN msg="gonfig: optional parser failed, skipped" parser=json`)
+		})
+	}
+
+	t.Run("cancelled load", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		err := gonfig.New(gonfig.Config{Args: args, Envs: []string{}},
+			gonfig.WithOptionalParser(gonfig.NewCustomContextParser("remote", func(context.Context, any) error {
+				cancel()
+
+				return context.Canceled
+			}))).LoadContext(ctx, &CustomLoaderConfig{})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+type mapSource struct {
+	name   gonfig.ParserType
+	values map[string]any
+	err    error
+
+	path     string
+	optional bool
+}
+
+func (m *mapSource) Type() gonfig.ParserType { return m.name }
+
+func (m *mapSource) LoadMap(context.Context) (map[string]any, error) { return m.values, m.err }
+
+type mapConfigSource struct {
+	mapSource
+}
+
+func (m *mapConfigSource) SetConfigPath(path string) { m.path = path }
+
+func (m *mapConfigSource) Optional() bool { return m.optional }
+
+type MapSourceConfig struct {
+	Address string        `json:"address" env:"ADDRESS" flag:"address" default:"localhost"`
+	Timeout time.Duration `json:"timeout" default:"5s"`
+	Debug   bool          `default:"true"`
+	Hosts   []string      `json:"hosts"`
+	Network net.IPNet     `json:"network"`
+
+	Database struct {
+		Name     string `json:"name"`
+		MaxConns int    `json:"max_conns" env:"MAX_CONNS"`
+	} `json:"db" env:"DB"`
+
+	Labels map[string]string `json:"labels"`
+}
+
+func TestMapSource(t *testing.T) {
+	source := &mapSource{name: "consul", values: map[string]any{
+		"ADDRESS": "consul:8080",
+		"timeout": "15s",
+		"debug":   nil,
+		"hosts":   "a,b",
+		"network": "10.0.0.0/8",
+		"db":      map[string]any{"name": "app", "max_conns": float64(10)},
+		"labels":  map[string]any{"env": "prod"},
+		"unknown": true,
+	}}
+
+	t.Run("merged by precedence", func(t *testing.T) {
+		var cfg MapSourceConfig
+		require.NoError(t, gonfig.New(gonfig.Config{
+			Envs: []string{"ADDRESS=env:8080", "DB_MAX_CONNS=5", "DB_NAME=env"},
+			Args: []string{"--address", "flag:8080"},
+		}, gonfig.WithMapSource(nil), gonfig.WithMapSource(source)).Load(&cfg))
+
+		require.Equal(t, "flag:8080", cfg.Address)
+		require.Equal(t, 15*time.Second, cfg.Timeout)
+		require.False(t, cfg.Debug, "nil value resets the default")
+		require.Equal(t, []string{"a", "b"}, cfg.Hosts)
+		require.Equal(t, "10.0.0.0/8", cfg.Network.String())
+		require.Equal(t, "app", cfg.Database.Name)
+		require.Equal(t, 10, cfg.Database.MaxConns)
+		require.Equal(t, map[string]string{"env": "prod"}, cfg.Labels)
+	})
+
+	t.Run("order", func(t *testing.T) {
+		var cfg MapSourceConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{"DB_MAX_CONNS=5"}, Args: []string{}},
+			gonfig.WithMapSource(source),
+			gonfig.WithOrder(gonfig.ParserDefaults, "consul", gonfig.ParserEnv, gonfig.ParserFlags)).Load(&cfg))
+
+		require.Equal(t, 5, cfg.Database.MaxConns)
+		require.Equal(t, "app", cfg.Database.Name)
+	})
+
+	t.Run("on its own", func(t *testing.T) {
+		cfg := MapSourceConfig{Address: "untouched"}
+		require.NoError(t, gonfig.NewMapParser(&mapSource{name: "consul", values: map[string]any{
+			"db": map[string]any{"name": "app"},
+		}}).Load(&cfg))
+
+		require.Equal(t, "untouched", cfg.Address)
+		require.Equal(t, "app", cfg.Database.Name)
+	})
+
+	t.Run("config path", func(t *testing.T) {
+		source := &mapConfigSource{mapSource: mapSource{name: "file", values: map[string]any{"address": "file"}}}
+
+		var cfg CustomLoaderConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{"--config", "config.json"}},
+			gonfig.WithMapSource(source)).Load(&cfg))
+
+		require.Equal(t, "config.json", source.path)
+	})
+}
+
+func TestMapSource_Errors(t *testing.T) {
+	broken := &mapSource{name: "consul", values: map[string]any{"timeout": "forever"}}
+
+	err := gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+		gonfig.WithMapSource(broken)).Load(&MapSourceConfig{})
+	require.ErrorContains(t, err, `gonfig: could not load: (consul) could not decode field "Timeout": `)
+	require.ErrorContains(t, err, `time: invalid duration "forever"`)
+
+	failed := errors.New("consul is down")
+	require.ErrorIs(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+		gonfig.WithMapSource(&mapSource{name: "consul", err: failed})).Load(&MapSourceConfig{}), failed)
+
+	var (
+		buf bytes.Buffer
+		cfg MapSourceConfig
+	)
+
+	optional := &mapConfigSource{mapSource: mapSource{name: "consul", optional: true, values: map[string]any{
+		"timeout": "forever",
+		"address": "consul",
+	}}}
+
+	require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+		gonfig.WithMapSource(optional), gonfig.WithLogger(newTestLogger(&buf))).Load(&cfg))
+
+	require.Equal(t, "consul", cfg.Address)
+	require.Equal(t, 5*time.Second, cfg.Timeout)
+	require.Contains(t, buf.String(), `level=WARN msg="gonfig: optional parser failed, skipped" parser=consul`)
+}
@@ -1,6 +1,7 @@
 package gonfig
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -22,13 +23,64 @@ const ErrEnvSetterBreak = constantError("break")
 // This would set the field to "localhost" if no other value is provided.
 const defaultTagName = "default"
 
+// defaultsParser is the parser of default values, it contributes values of "default" tags
+// to the layers of the current load.
+type defaultsParser struct{}
+
 // newDefaultParser creates a new parser for handling default values.
 // It returns a Parser implementation that sets default values to struct fields
 // based on the "default" struct tags.
 func newDefaultParser() Parser {
-	return &parserFunc{name: ParserDefaults, call: SetDefaults}
+	return &defaultsParser{}
+}
+
+// Type returns the type of the defaults parser.
+func (p *defaultsParser) Type() ParserType { return ParserDefaults }
+
+// Load sets default values to the fields of the destination, see SetDefaults.
+func (p *defaultsParser) Load(dest interface{}) error {
+	return p.LoadContext(context.Background(), dest)
+}
+
+// LoadContext contributes default values of zero fields of the destination to the layers
+// of the current load. Default values are parsed by SetDefaults, so they keep their syntax.
+func (p *defaultsParser) LoadContext(ctx context.Context, dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr {
+		return SetDefaults(dest)
+	}
+
+	scratch := reflect.New(rv.Type().Elem())
+	if err := SetDefaults(scratch.Interface()); err != nil {
+		return err
+	}
+
+	zeros := make(map[string]bool)
+	for elem, err := range ReflectFieldsOf(dest, treeOptions) {
+		if err != nil {
+			return fmt.Errorf("(defaults) %w", err)
+		}
+
+		zeros[elem.Path()] = elem.Value.IsZero()
+	}
+
+	tree := make(keyTree)
+	for elem, err := range ReflectFieldsOf(scratch.Interface(), treeOptions) {
+		if err != nil {
+			return fmt.Errorf("(defaults) %w", err)
+		}
+
+		if path, raw := elem.Path(), elem.Field.Tag.Get(defaultTagName); zeros[path] && raw != "" {
+			tree[path] = treeValue{value: elem.Value.Interface(), raw: rawString(elem, raw)}
+		}
+	}
+
+	return contribute(ctx, layer{source: ParserDefaults, tree: tree}, dest)
 }
 
+// needsDest reports true, default values are set only to zero fields of the destination.
+func (p *defaultsParser) needsDest() bool { return true }
+
 // SetDefaults sets default values to the fields of the provided struct.
 // It recursively processes struct fields and assigns default values based on
 // the "default" tag. It supports setting values for basic types, slices, arrays, maps,
@@ -41,15 +93,15 @@ func SetDefaults(dest interface{}) error {
 			return fmt.Errorf("(defaults) %w", err)
 		}
 
-		value := elem.Field.Tag.Get(defaultTagName)
-		if err = tryCustomTypes(elem.Value, value); errors.Is(err, ErrEnvSetterBreak) {
+		value, field := elem.Field.Tag.Get(defaultTagName), secretOf(elem.Value)
+		if err = tryCustomTypes(field, value); errors.Is(err, ErrEnvSetterBreak) {
 			continue
 		} else if err != nil {
-			return fmt.Errorf("(defaults) failed to set field %q: %w", elem.Field.Name, err)
+			return fmt.Errorf("(defaults) failed to set field %q: %w", elem.Field.Name, redactError(elem, err))
 		}
 
-		if err = setDefaultValue(elem.Value, value); err != nil {
-			return fmt.Errorf("(defaults) failed to set field %q: %w", elem.Field.Name, err)
+		if err = setDefaultValue(field, value); err != nil {
+			return fmt.Errorf("(defaults) failed to set field %q: %w", elem.Field.Name, redactError(elem, err))
 		}
 	}
 
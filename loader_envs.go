@@ -1,14 +1,18 @@
 package gonfig
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"reflect"
+	"slices"
 	"strings"
+	"unicode"
 
 	"github.com/go-viper/mapstructure/v2"
-	"github.com/spf13/pflag"
 )
 
 // EnvUsageOption defines a function type used to configure options for environment variable usage.
@@ -16,7 +20,10 @@ type EnvUsageOption func(*envUsageOptions)
 
 // envUsageOptions holds configuration options for generating environment variable usage information.
 type envUsageOptions struct {
-	prefix string // Optional prefix to be added to environment variable names.
+	prefixes []string // Optional prefixes to be added to environment variable names, in order of precedence.
+	auto     bool     // Derive names of untagged fields from their Go names, see Config.AutoEnv.
+
+	delimiter string // Delimiter of nested names, see Config.EnvDelimiter.
 }
 
 // envUsage represents metadata about an environment variable, including its name, usage description, and type.
@@ -47,25 +54,235 @@ const (
 	// Example usage: `env:"DB_HOST"`
 )
 
+// envParser is the parser of environment variables, it contributes raw values of variables
+// to the layers of the current load, they are decoded with the same hooks as LoadEnvs.
+type envParser struct {
+	envs      []string
+	prefixes  []string // in order of precedence, see Config.EnvPrefixes.
+	auto      bool
+	delimiter string
+	schema    bool
+}
+
 // newEnvLoader creates a new parser that loads configuration from environment variables.
-// It uses the provided environment variable slice and prefix to populate the configuration.
-// Returns a Parser that processes environment variables with the specified prefix.
-func newEnvLoader(envs []string, prefix string) Parser {
-	return &parserFunc{name: ParserEnv, call: func(v interface{}) error {
-		return LoadEnvs(PrepareEnvs(envs, prefix), v)
-	}}
+// It uses environment variables, prefixes and the naming options (see Config.AutoEnv,
+// Config.EnvDelimiter and Config.EnvSchema) of the provided config to populate the configuration.
+// Returns a Parser that processes environment variables with the specified prefixes.
+func newEnvLoader(c Config) Parser {
+	return &envParser{envs: c.Envs, prefixes: c.envPrefixes(), auto: c.AutoEnv, delimiter: c.envNesting(), schema: c.EnvSchema}
+}
+
+// Type returns the type of the environment variables parser.
+func (p *envParser) Type() ParserType { return ParserEnv }
+
+// Load loads environment variables into the destination.
+func (p *envParser) Load(dest interface{}) error {
+	return p.LoadContext(context.Background(), dest)
+}
+
+// LoadContext resolves environment variables of the fields of the destination, like LoadEnvs does
+// (by "env" tags or Go names of fields and their owners), and contributes them to the layers of the current load.
+// Every field is resolved with the first prefix (in order of precedence) that has its variable.
+func (p *envParser) LoadContext(ctx context.Context, dest interface{}) error {
+	tree := make(keyTree)
+	for _, prefix := range slices.Backward(p.prefixes) {
+		items, err := p.tree(dest, prefix)
+		if err != nil {
+			return fmt.Errorf("(env) %w", err)
+		}
+
+		maps.Copy(tree, items)
+	}
+
+	return contribute(ctx, layer{source: ParserEnv, tag: envTag, tree: tree}, dest)
+}
+
+// tree resolves environment variables with the prefix of the fields of the destination.
+func (p *envParser) tree(dest any, prefix string) (keyTree, error) {
+	key := func(name string) string {
+		if prefix != "" {
+			return prefix + envDelimiter + name
+		}
+
+		return name
+	}
+
+	if p.schema {
+		return p.schemaTree(dest, prefix, key)
+	}
+
+	return rawTree(prepareEnvs(p.envs, prefix, p.delimiter), dest, envNames(p.auto), func(keys []string) string {
+		return key(strings.Join(keys, p.delimiter))
+	})
 }
 
-// EnvUsageWithPrefix creates an EnvUsageOption that sets a prefix for environment variables.
-// This prefix is applied to each environment variable name when generating usage information.
+// needsDest reports false, environment variables do not depend on the destination.
+func (p *envParser) needsDest() bool { return false }
+
+// schemaTree resolves environment variables of the fields of the destination by their full names:
+// names of the field and its owners (see envNames) joined by the delimiter. Entries of map fields are
+// resolved from variables named `NAME<delimiter>KEY`. Names are matched exactly and then
+// case-insensitively (in sorted order), the key function builds the name with the prefix.
+func (p *envParser) schemaTree(dest any, prefix string, key func(name string) string) (keyTree, error) {
+	envs := make(map[string]string, len(p.envs))
+	for _, env := range p.envs {
+		name, value, ok := strings.Cut(env, envPairDelim)
+		if ok && prefix != "" {
+			name, ok = strings.CutPrefix(name, prefix+envDelimiter)
+		}
+
+		if ok {
+			envs[name] = value
+		}
+	}
+
+	sorted := slices.Sorted(maps.Keys(envs))
+	names := envNames(p.auto)
+
+	tree := make(keyTree)
+	for elem, err := range ReflectFieldsOf(dest, treeOptions) {
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range envSchemaNames(elem, names, p.delimiter) {
+			if secretOf(elem.Value).Kind() == reflect.Map {
+				if entries := envSchemaMap(envs, sorted, name+p.delimiter); len(entries) > 0 {
+					tree[elem.Path()] = treeValue{value: entries, key: key(name), raw: rawString(elem, entries)}
+
+					break
+				}
+			}
+
+			if matched, ok := envSchemaLookup(envs, sorted, name); ok {
+				tree[elem.Path()] = treeValue{value: envs[matched], key: key(matched), raw: rawString(elem, envs[matched])}
+
+				break
+			}
+		}
+	}
+
+	return tree, nil
+}
+
+// envSchemaNames returns candidates of the full name of the field: every combination of names of
+// the field and its owners, joined by the delimiter. Embedded structs without names are squashed,
+// it returns nil if the field or any of its owners has no names (e.g. is tagged with `env:"-"`).
+func envSchemaNames(elem *ReflectValue, names func(field reflect.StructField) []string, delimiter string) []string {
+	out := []string{""}
+	for _, item := range chainOf(elem) {
+		candidates := names(item.Field)
+		if len(candidates) == 0 && item.Field.Anonymous {
+			continue
+		} else if len(candidates) == 0 {
+			return nil
+		}
+
+		next := make([]string, 0, len(out)*len(candidates))
+		for _, prefix := range out {
+			for _, name := range candidates {
+				if prefix != "" {
+					name = prefix + delimiter + name
+				}
+
+				next = append(next, name)
+			}
+		}
+
+		out = next
+	}
+
+	return out
+}
+
+// envSchemaLookup returns the name of the variable that matches the name, exactly first
+// and then case-insensitively.
+func envSchemaLookup(envs map[string]string, sorted []string, name string) (string, bool) {
+	if _, ok := envs[name]; ok {
+		return name, true
+	}
+
+	for _, key := range sorted {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+// envSchemaMap collects entries of the map field from variables that start with the prefix
+// (case-insensitively), the rest of the name is the key of the entry.
+func envSchemaMap(envs map[string]string, sorted []string, prefix string) map[string]any {
+	out := make(map[string]any)
+	for _, name := range sorted {
+		if len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			out[name[len(prefix):]] = envs[name]
+		}
+	}
+
+	return out
+}
+
+// envNames returns a function that returns names of the field for lookupTree. When auto is set,
+// untagged fields are also matched by their Go names in SCREAMING_SNAKE case (see envAutoName).
+func envNames(auto bool) func(field reflect.StructField) []string {
+	names := tagNames(envTag)
+
+	return func(field reflect.StructField) []string {
+		out := names(field)
+		if !auto || len(out) == 0 || field.Tag.Get(envTag) != "" {
+			return out
+		}
+
+		return append([]string{envAutoName(field.Name)}, out...)
+	}
+}
+
+// envAutoName converts the Go name of the field to SCREAMING_SNAKE case, acronyms are kept
+// together, e.g. "MaxConns" -> "MAX_CONNS", "HTTPServer" -> "HTTP_SERVER", "UserID" -> "USER_ID".
+func envAutoName(name string) string {
+	runes := []rune(name)
+
+	var out strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && next {
+				out.WriteString(envDelimiter)
+			}
+		}
+
+		out.WriteRune(unicode.ToUpper(r))
+	}
+
+	return out.String()
+}
+
+// EnvUsageWithPrefix creates an EnvUsageOption that sets prefixes for environment variables.
+// Prefixes are applied to each environment variable name when generating usage information,
+// every variable is listed with all prefixes in order of precedence (see Config.EnvPrefixes).
 //
 // Parameters:
-//   - prefix: The string prefix to add to environment variable names.
+//   - prefixes: The string prefixes to add to environment variable names.
 //
 // Returns:
-//   - EnvUsageOption: A function that modifies the prefix in envUsageOptions.
-func EnvUsageWithPrefix(prefix string) EnvUsageOption {
-	return func(opts *envUsageOptions) { opts.prefix = prefix }
+//   - EnvUsageOption: A function that modifies prefixes in envUsageOptions.
+func EnvUsageWithPrefix(prefixes ...string) EnvUsageOption {
+	return func(opts *envUsageOptions) { opts.prefixes = Config{EnvPrefixes: prefixes}.envPrefixes() }
+}
+
+// EnvUsageWithAutoEnv creates an EnvUsageOption that lists fields without the "env" tag under names
+// derived from their Go names, like the loader resolves them when `Config.AutoEnv` is set.
+func EnvUsageWithAutoEnv() EnvUsageOption {
+	return func(opts *envUsageOptions) { opts.auto = true }
+}
+
+// EnvUsageWithDelimiter creates an EnvUsageOption that joins names of nested structs and their fields
+// with the delimiter, like the loader does with `Config.EnvDelimiter`.
+func EnvUsageWithDelimiter(delimiter string) EnvUsageOption {
+	return func(opts *envUsageOptions) { opts.delimiter = delimiter }
 }
 
 // UsageOfEnvs generates a human-readable string that describes the environment variables
@@ -84,6 +301,11 @@ func EnvUsageWithPrefix(prefix string) EnvUsageOption {
 // generating usage information based on the tags. If a struct field is another struct, it recurses
 // into the nested fields.
 func UsageOfEnvs(dest any, opts ...EnvUsageOption) string {
+	var options envUsageOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	output := make([]envUsage, 0)
 	exists := make(map[string]struct{})
 	for field, err := range ReflectFieldsOf(dest, ReflectOptions{CanSet: True()}) {
@@ -91,26 +313,7 @@ func UsageOfEnvs(dest any, opts ...EnvUsageOption) string {
 			return ""
 		}
 
-		var name string
-		for parent := field; parent != nil; parent = parent.Owner {
-			env := parent.Field.Tag.Get("env")
-			if tmp := strings.Split(env, ","); len(tmp) > 0 {
-				env = tmp[0]
-			}
-
-			if env == "" {
-				continue
-			}
-
-			if name == "" {
-				name = env
-
-				continue
-			}
-
-			name = env + envDelimiter + name
-		}
-
+		name := envFieldName(field, options.auto, Config{EnvDelimiter: options.delimiter}.envNesting())
 		if name == "" {
 			continue
 		}
@@ -127,86 +330,148 @@ func UsageOfEnvs(dest any, opts ...EnvUsageOption) string {
 		}
 
 		if tmp := field.Field.Tag.Get(defaultTagName); tmp != "" {
-			usage += fmt.Sprintf(" (default: %s)", tmp)
+			usage += fmt.Sprintf(" (default: %s)", rawString(field, tmp))
 		}
 
-		output = append(output, envUsage{Usage: usage, Name: name, Type: field.Value.Type().String()})
-	}
-
-	var options envUsageOptions
-	for _, opt := range opts {
-		opt(&options)
+		output = append(output, envUsage{Usage: usage, Name: name, Type: secretOf(field.Value).Type().String()})
 	}
 
-	var prefix string
-	if options.prefix != "" {
-		prefix = options.prefix + envDelimiter
+	prefixes := options.prefixes
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
 	}
 
 	var out []string
 	for _, item := range output {
-		out = append(out, fmt.Sprintf("  - '%s%s' <%s>%s", prefix, item.Name, item.Type, item.Usage))
+		names := make([]string, 0, len(prefixes))
+		for _, prefix := range prefixes {
+			if prefix != "" {
+				prefix += envDelimiter
+			}
+
+			names = append(names, "'"+prefix+item.Name+"'")
+		}
+
+		out = append(out, fmt.Sprintf("  - %s <%s>%s", strings.Join(names, ", "), item.Type, item.Usage))
 	}
 
 	return fmt.Sprintf("Environment variables:\n%s", strings.Join(out, "\n"))
 }
 
+// envFieldName builds the name of the environment variable for the field from the "env" tags
+// of the field and all its owners, joined by envDelimiter (e.g. "EMBED_INT_FIELD").
+// It returns an empty string if the field has no "env" tag. When auto is set, untagged fields and
+// owners are named by envAutoName (embedded structs are squashed), and fields tagged with `env:"-"`
+// (or owned by such a struct) have no name.
+func envFieldName(field *ReflectValue, auto bool, delimiter string) string {
+	var name string
+	for parent := field; parent != nil; parent = parent.Owner {
+		env := parent.Field.Tag.Get(envTag)
+		if tmp := strings.Split(env, ","); len(tmp) > 0 {
+			env = tmp[0]
+		}
+
+		switch {
+		case auto && env == "-":
+			return ""
+		case auto && env == "" && parent.Field.Name != "" && !parent.Field.Anonymous:
+			env = envAutoName(parent.Field.Name)
+		case env == "":
+			continue
+		}
+
+		if name == "" {
+			name = env
+
+			continue
+		}
+
+		name = env + delimiter + name
+	}
+
+	return name
+}
+
 // wrapUsageLoader wraps the provided loader function to add additional functionality
-// for handling help flags and printing environment variable usage. It ensures that when
-// the help flag (`--help`) is provided, the program prints the environment variable usage
-// and exits gracefully. This function is typically used to augment the configuration loading
-// mechanism.
+// for handling help flags and printing environment variable usage. When the help flag (`--help`)
+// is provided, it appends the environment variable usage to the rendered flags usage.
 //
 // The wrapped handler function behaves as follows:
-//  1. If the handler returns an error equal to `pflag.ErrHelp`, it prints environment variable
-//     usage (with an optional prefix) and terminates the program.
-//  2. If any other error occurs during the handler execution, the error is returned.
-//  3. On successful execution of the handler without errors, it proceeds normally.
+//  1. If the handler returns ErrHelp, the environment variable usage (with an optional prefix)
+//     is appended to its Usage.
+//  2. If exit on help is enabled (`Config.ExitOnHelp` or `WithCustomExit`), the usage is printed
+//     to `Config.Output` (or os.Stdout) and the program is terminated.
+//  3. Otherwise, the usage is printed to `Config.Output` if it is set, and ErrHelp is returned,
+//     so the caller decides how to proceed.
+//  4. If any other error occurs during the handler execution, the error is returned.
 //
 // Params:
-// - svc: The *loader, which contains the `EnvPrefix` and an optional custom exit function.
+// - svc: The *loader, which contains the `EnvPrefix`, the output and an optional custom exit function.
 // - handler: The function responsible for loading the configuration (e.g., from flags or envs).
 //
 // Returns:
 // - A new function that wraps the original handler with additional error handling and help output logic.
-func wrapUsageLoader(svc *loader, handler func(v any) error) func(v any) error {
-	return func(v any) error {
+func wrapUsageLoader(svc *loader, handler func(ctx context.Context, v any) error) func(ctx context.Context, v any) error {
+	return func(ctx context.Context, v any) error {
 		// Attempt to load the configuration
-		if err := handler(v); errors.Is(err, pflag.ErrHelp) {
-			// If the error is the help flag, print environment variable usage
-			fmt.Println()
-			fmt.Println(UsageOfEnvs(v, EnvUsageWithPrefix(svc.EnvPrefix)))
-
-			// Handle program exit for tests or production
-			if svc.exit != nil {
-				svc.exit(0)
-				return nil // allows tests to proceed without terminating the program
-			}
-
-			// If no custom exit function is provided, exit the program
-			os.Exit(0)
-		} else if err != nil {
+		var help *ErrHelp
+		if err := handler(ctx, v); !errors.As(err, &help) {
 			// Return any other errors from the loader
 			return err
 		}
 
+		// If the error is the help flag, append environment variable usage
+		options := []EnvUsageOption{EnvUsageWithPrefix(svc.envPrefixes()...), EnvUsageWithDelimiter(svc.EnvDelimiter)}
+		if svc.AutoEnv {
+			options = append(options, EnvUsageWithAutoEnv())
+		}
+
+		help.Usage += "\n" + UsageOfEnvs(v, options...) + "\n"
+
+		output := svc.Output
+		if output == nil && svc.ExitOnHelp {
+			output = os.Stdout
+		}
+
+		if output != nil {
+			_, _ = io.WriteString(output, help.Usage)
+		}
+
+		if !svc.ExitOnHelp {
+			return help
+		}
+
+		// Handle program exit for tests or production
+		if svc.exit != nil {
+			svc.exit(0)
+			return nil // allows tests to proceed without terminating the program
+		}
+
+		// If no custom exit function is provided, exit the program
+		os.Exit(0)
+
 		return nil
 	}
 }
 
 // PrepareEnvs prepares a map from the given environment variable slice.
-// It filters and parses the environment variables based on the provided prefix.
+// It filters and parses the environment variables based on the provided prefix: only variables that
+// start with the prefix followed by "_" are used (e.g. prefix "APP" does not match `APPLE_KEY`).
 // The resulting map has a nested structure based on the environment variable names,
 // using the specified delimiter for nesting.
 func PrepareEnvs(envs []string, prefix string) map[string]interface{} {
+	return prepareEnvs(envs, prefix, envDelimiter)
+}
+
+// prepareEnvs is PrepareEnvs with the custom delimiter of nested names, see Config.EnvDelimiter.
+func prepareEnvs(envs []string, prefix, delimiter string) map[string]interface{} {
 	out := make(map[string]interface{}, len(envs))
 	for _, env := range envs {
-		if prefix != "" && !strings.HasPrefix(env, prefix) {
-			continue
-		}
-
 		if prefix != "" {
-			env = strings.TrimPrefix(env, prefix+envDelimiter)
+			var ok bool
+			if env, ok = strings.CutPrefix(env, prefix+envDelimiter); !ok {
+				continue
+			}
 		}
 
 		parts := strings.SplitN(env, envPairDelim, 2)
@@ -214,10 +479,10 @@ func PrepareEnvs(envs []string, prefix string) map[string]interface{} {
 			continue
 		}
 
-		keys := strings.Split(parts[0], envDelimiter)
+		keys := strings.Split(parts[0], delimiter)
 
 		// Insert into map with the correct nesting
-		insertIntoMap(out, keys, parts[1])
+		insertIntoMap(out, keys, parts[1], delimiter)
 	}
 
 	return out
@@ -226,13 +491,13 @@ func PrepareEnvs(envs []string, prefix string) map[string]interface{} {
 // insertIntoMap inserts the value into the map with the specified keys.
 // The keys define the nesting level of the map. If the keys are exhausted, the value is set.
 // This function creates nested maps as needed to match the structure defined by the keys.
-func insertIntoMap(m map[string]interface{}, keys []string, value interface{}) {
+func insertIntoMap(m map[string]interface{}, keys []string, value interface{}, delimiter string) {
 	if len(keys) == 1 {
 		m[keys[0]] = value
 		return
 	}
 
-	m[strings.Join(keys, envDelimiter)] = value
+	m[strings.Join(keys, delimiter)] = value
 
 	// Create a nested map if it does not exist
 	if _, ok := m[keys[0]]; !ok {
@@ -240,7 +505,7 @@ func insertIntoMap(m map[string]interface{}, keys []string, value interface{}) {
 	}
 
 	if nestedMap, ok := m[keys[0]].(map[string]interface{}); ok {
-		insertIntoMap(nestedMap, keys[1:], value)
+		insertIntoMap(nestedMap, keys[1:], value, delimiter)
 	}
 }
 
@@ -312,3 +577,52 @@ func LoadEnvs(envs map[string]interface{}, dest any) error {
 
 	return nil
 }
+
+// ToEnvs renders the destination as environment variables with the provided prefix, so it is
+// the inverse of PrepareEnvs and LoadEnvs: the result can be passed as `Config.Envs` (or to a child
+// process) to load the same configuration. Names are resolved like the env parser does, e.g. field
+// `Field` of the struct `Embed` becomes `PREFIX_EMBED_FIELD`, fields tagged with `env:"-"` are skipped.
+//
+// Slices are joined by commas, keys of maps are appended to the name. Nil pointers, nil slices and
+// zero structs (e.g. net.IPNet) have no textual form and are omitted, as well as slices with items
+// that contain commas, because they can not be read back. Values of secret fields are NOT redacted,
+// use Export to show the configuration. It returns nil if the destination is not a pointer to a struct.
+//
+// Options of Export are applied as well, e.g. ExportWithDelimiter for a loader with `Config.EnvDelimiter`,
+// the provided prefix replaces the one of ExportWithPrefix.
+func ToEnvs(dest any, prefix string, options ...ExportOption) []string {
+	var opts exportOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	opts.secrets = true
+
+	entries, err := exportEntries(dest, opts)
+	if err != nil {
+		return nil
+	}
+
+	if prefix != "" {
+		prefix += envDelimiter
+	}
+
+	delimiter := Config{EnvDelimiter: opts.envDelimiter}.envNesting()
+
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := envName(entry.elem, false, delimiter)
+		if !ok {
+			continue
+		}
+
+		lines, err := envLines(prefix+name, reflect.ValueOf(entry.value), delimiter, true)
+		if err != nil {
+			continue
+		}
+
+		out = append(out, lines...)
+	}
+
+	return out
+}
@@ -1,6 +1,8 @@
 package gonfig_test
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"net"
 	"reflect"
@@ -8,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
 
 	"github.com/im-kulikov/gonfig"
@@ -199,3 +202,299 @@ func Test_setMultipleFields(t *testing.T) {
 	require.Equal(t, "value", example.FieldTwo)
 	require.Equal(t, "value", example.Nested.FieldThree)
 }
+
+func TestToEnvs(t *testing.T) {
+	type Config struct {
+		Name    string                `env:"NAME"`
+		Port    int                   // untagged fields are resolved by upper-cased names
+		Timeout time.Duration         `env:"TIMEOUT"`
+		Hosts   []string              `env:"HOSTS"`
+		Ports   []int                 `env:"PORTS"`
+		Labels  map[string]string     `env:"LABELS"`
+		Token   gonfig.Secret[string] `env:"TOKEN"`
+		Skipped string                `env:"-"`
+		IP      net.IP                `env:"IP"`
+		Network net.IPNet             `env:"NETWORK"`
+
+		Embed struct {
+			IntField int `env:"INT_FIELD"`
+		} `env:"EMBED"`
+	}
+
+	cfg := Config{
+		Name:    "service",
+		Port:    8080,
+		Timeout: 5 * time.Second,
+		Hosts:   []string{"a", "b"},
+		Labels:  map[string]string{"team": "core"},
+		Token:   gonfig.NewSecret("p@ss"),
+		Skipped: "skipped",
+	}
+
+	cfg.Embed.IntField = 42
+
+	envs := gonfig.ToEnvs(&cfg, "APP")
+	require.Equal(t, []string{
+		"APP_NAME=service",
+		"APP_PORT=8080",
+		"APP_TIMEOUT=5s",
+		"APP_HOSTS=a,b",
+		"APP_LABELS_team=core",
+		"APP_TOKEN=p@ss",
+		"APP_EMBED_INT_FIELD=42",
+	}, envs)
+
+	var out Config
+	require.NoError(t, gonfig.New(gonfig.Config{EnvPrefix: "APP", Envs: envs, Args: []string{}}).Load(&out))
+
+	cfg.Skipped = ""
+	require.Equal(t, cfg, out)
+
+	require.Equal(t, []string{"NAME=service"}, gonfig.ToEnvs(&struct {
+		Name string `env:"NAME"`
+	}{Name: "service"}, ""))
+	require.Nil(t, gonfig.ToEnvs(cfg, "APP"))
+
+	t.Run("zero values", func(t *testing.T) {
+		envs := gonfig.ToEnvs(&Config{}, "APP")
+		require.NotContains(t, strings.Join(envs, "\n"), "<nil>")
+
+		out := Config{Name: "overridden", IP: net.ParseIP("10.0.0.1")}
+		require.NoError(t, gonfig.New(gonfig.Config{EnvPrefix: "APP", Envs: envs, Args: []string{}}).Load(&out))
+		require.Equal(t, Config{IP: net.ParseIP("10.0.0.1")}, out, "nil values are omitted, zero values are rendered")
+	})
+
+	t.Run("commas", func(t *testing.T) {
+		envs := gonfig.ToEnvs(&Config{Name: "a,b", Hosts: []string{"a,b", "c"}}, "")
+		require.Contains(t, envs, "NAME=a,b")
+		require.NotContains(t, strings.Join(envs, "\n"), "HOSTS=", "items with commas can not be read back")
+	})
+
+	t.Run("multiline", func(t *testing.T) {
+		envs := gonfig.ToEnvs(&Config{Name: "a\nb"}, "")
+		require.Contains(t, envs, "NAME=a\nb")
+
+		var out Config
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: envs, Args: []string{}}).Load(&out))
+		require.Equal(t, "a\nb", out.Name)
+	})
+}
+
+func TestAutoEnv(t *testing.T) {
+	type Common struct {
+		LogLevel string
+	}
+
+	type Config struct {
+		Common
+
+		HTTPServer string
+		UserID     int
+		Timeout    time.Duration `env:"TTL"`
+		Ignored    string        `env:"-"`
+
+		Database struct {
+			MaxConns int
+			Host     string `env:"ADDR"`
+		}
+	}
+
+	envs := []string{
+		"APP_LOG_LEVEL=debug",
+		"APP_HTTP_SERVER=:8080",
+		"APP_USER_ID=42",
+		"APP_TTL=5s",
+		"APP_IGNORED=ignored",
+		"APP_DATABASE_MAX_CONNS=10",
+		"APP_DATABASE_ADDR=db",
+	}
+
+	t.Run("load", func(t *testing.T) {
+		var cfg Config
+		require.NoError(t, gonfig.New(gonfig.Config{EnvPrefix: "APP", AutoEnv: true, Envs: envs, Args: []string{}}).Load(&cfg))
+
+		expect := Config{Common: Common{LogLevel: "debug"}, HTTPServer: ":8080", UserID: 42, Timeout: 5 * time.Second}
+		expect.Database.MaxConns = 10
+		expect.Database.Host = "db"
+		require.Equal(t, expect, cfg)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var cfg Config
+		require.NoError(t, gonfig.New(gonfig.Config{EnvPrefix: "APP", Envs: envs, Args: []string{}}).Load(&cfg))
+		require.Empty(t, cfg.HTTPServer)
+		require.Zero(t, cfg.Database.MaxConns)
+		require.Equal(t, 5*time.Second, cfg.Timeout)
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		require.Equal(t, `Environment variables:
+  - 'APP_HTTP_SERVER' <string>
+  - 'APP_USER_ID' <int>
+  - 'APP_TTL' <time.Duration>
+  - 'APP_LOG_LEVEL' <string>
+  - 'APP_DATABASE_MAX_CONNS' <int>
+  - 'APP_DATABASE_ADDR' <string>`, gonfig.UsageOfEnvs(&Config{}, gonfig.EnvUsageWithPrefix("APP"), gonfig.EnvUsageWithAutoEnv()))
+
+		var buf bytes.Buffer
+		err := gonfig.New(gonfig.Config{EnvPrefix: "APP", AutoEnv: true, Envs: []string{}, Args: []string{"--help"}, Output: &buf}).Load(&Config{})
+		require.ErrorIs(t, err, pflag.ErrHelp)
+		require.Contains(t, buf.String(), "'APP_DATABASE_MAX_CONNS' <int>")
+	})
+}
+
+func TestEnvDelimiter(t *testing.T) {
+	type Config struct {
+		MaxConns int               `env:"MAX_CONNS"`
+		Labels   map[string]string `env:"LABELS"`
+		Ignored  string            `env:"-"`
+
+		Max struct {
+			Conns int `env:"CONNS"`
+		} `env:"MAX"`
+	}
+
+	envs := []string{
+		"APP_MAX_CONNS=10",
+		"APP_MAX__CONNS=3",
+		"APP_LABELS__team=core",
+		"APP_LABELS__owner_name=platform",
+		"APP_IGNORED=ignored",
+		"APPLE_MAX_CONNS=1",
+	}
+
+	expect := Config{MaxConns: 10, Labels: map[string]string{"team": "core", "owner_name": "platform"}}
+	expect.Max.Conns = 3
+
+	for _, schema := range []bool{false, true} {
+		t.Run(fmt.Sprintf("schema=%t", schema), func(t *testing.T) {
+			var cfg Config
+			loader := gonfig.New(gonfig.Config{EnvPrefix: "APP", EnvDelimiter: "__", EnvSchema: schema, Envs: envs, Args: []string{}})
+			sources, err := loader.LoadExplain(context.Background(), &cfg)
+			require.NoError(t, err)
+			require.Equal(t, expect, cfg)
+
+			require.Contains(t, sources, gonfig.FieldSource{Path: "MaxConns", Source: gonfig.ParserEnv, Key: "APP_MAX_CONNS", Raw: "10"})
+			require.Contains(t, sources, gonfig.FieldSource{Path: "Max.Conns", Source: gonfig.ParserEnv, Key: "APP_MAX__CONNS", Raw: "3"})
+		})
+	}
+
+	t.Run("usage", func(t *testing.T) {
+		require.Equal(t, `Environment variables:
+  - 'APP_MAX_CONNS' <int>
+  - 'APP_LABELS' <map[string]string>
+  - 'APP_MAX__CONNS' <int>`, gonfig.UsageOfEnvs(&Config{},
+			gonfig.EnvUsageWithPrefix("APP"), gonfig.EnvUsageWithDelimiter("__"), gonfig.EnvUsageWithAutoEnv()))
+	})
+}
+
+func TestEnvDelimiter_RoundTrip(t *testing.T) {
+	type Config struct {
+		MaxConns int
+		Labels   map[string]string `env:"LABELS"`
+
+		DB struct {
+			MaxConns int
+		}
+	}
+
+	cfg := Config{MaxConns: 5, Labels: map[string]string{"team_name": "core"}}
+	cfg.DB.MaxConns = 10
+
+	envs := gonfig.ToEnvs(&cfg, "APP", gonfig.ExportWithDelimiter("__"))
+	require.Equal(t, []string{"APP_MAXCONNS=5", "APP_LABELS__team_name=core", "APP_DB__MAXCONNS=10"}, envs)
+
+	var buf bytes.Buffer
+	require.NoError(t, gonfig.Export(&cfg, gonfig.FormatEnv, &buf, gonfig.ExportWithPrefix("APP"), gonfig.ExportWithDelimiter("__")))
+	require.Equal(t, strings.Join(envs, "\n")+"\n", buf.String())
+
+	for _, schema := range []bool{false, true} {
+		t.Run(fmt.Sprintf("schema=%t", schema), func(t *testing.T) {
+			var out Config
+			require.NoError(t, gonfig.New(gonfig.Config{EnvPrefix: "APP", EnvDelimiter: "__", EnvSchema: schema, Envs: envs, Args: []string{}}).Load(&out))
+			require.Equal(t, cfg, out)
+		})
+	}
+}
+
+func TestEnvSchema(t *testing.T) {
+	type Config struct {
+		Max      string
+		MaxConns int `env:"MAX_CONNS"`
+
+		Database struct {
+			Host string
+		}
+	}
+
+	envs := []string{"MAX_CONNS=10", "MAX=5", "DATABASE_HOST=db", "DATABASE=oops"}
+
+	t.Run("schema", func(t *testing.T) {
+		var cfg Config
+		require.NoError(t, gonfig.New(gonfig.Config{EnvSchema: true, AutoEnv: true, Envs: envs, Args: []string{}}).Load(&cfg))
+
+		expect := Config{Max: "5", MaxConns: 10}
+		expect.Database.Host = "db"
+		require.Equal(t, expect, cfg)
+	})
+
+	t.Run("guessing", func(t *testing.T) {
+		// the nested map of DATABASE is replaced by its own variable, so the field is not resolved.
+		var cfg Config
+		require.NoError(t, gonfig.New(gonfig.Config{AutoEnv: true, Envs: envs, Args: []string{}}).Load(&cfg))
+		require.Empty(t, cfg.Database.Host)
+	})
+}
+
+func TestEnvPrefixes(t *testing.T) {
+	type Config struct {
+		Host string `env:"HOST" usage:"service host"`
+		Port int    `env:"PORT"`
+		Key  string `env:"KEY"`
+	}
+
+	envs := []string{
+		"PLATFORM_HOST=platform",
+		"PLATFORM_PORT=80",
+		"BILLING_PORT=8080",
+		"BILLINGS_KEY=wrong",
+		"APPLE_KEY=wrong",
+	}
+
+	t.Run("exact", func(t *testing.T) {
+		require.Empty(t, gonfig.PrepareEnvs([]string{"APPLE_KEY=wrong"}, "APP"))
+		require.Equal(t, map[string]any{"KEY": "right"}, gonfig.PrepareEnvs([]string{"APPLE_KEY=wrong", "APP_KEY=right"}, "APP"))
+	})
+
+	t.Run("precedence", func(t *testing.T) {
+		var cfg Config
+		loader := gonfig.New(gonfig.Config{EnvPrefixes: []string{"BILLING", "PLATFORM"}, Envs: envs, Args: []string{}})
+		sources, err := loader.LoadExplain(context.Background(), &cfg)
+		require.NoError(t, err)
+		require.Equal(t, Config{Host: "platform", Port: 8080}, cfg)
+		require.Equal(t, []gonfig.FieldSource{
+			{Path: "Host", Source: gonfig.ParserEnv, Key: "PLATFORM_HOST", Raw: "platform"},
+			{Path: "Port", Source: gonfig.ParserEnv, Key: "BILLING_PORT", Raw: "8080"},
+		}, sources)
+
+		cfg = Config{}
+		require.NoError(t, gonfig.New(gonfig.Config{EnvPrefix: "PLATFORM", EnvPrefixes: []string{"BILLING"}, Envs: envs, Args: []string{}}).Load(&cfg))
+		require.Equal(t, Config{Host: "platform", Port: 80}, cfg, "EnvPrefix takes precedence")
+
+		cfg = Config{}
+		require.NoError(t, gonfig.New(gonfig.Config{EnvPrefixes: []string{"BILLING", "PLATFORM"}, EnvSchema: true, Envs: envs, Args: []string{}}).Load(&cfg))
+		require.Equal(t, Config{Host: "platform", Port: 8080}, cfg)
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		require.Equal(t, `Environment variables:
+  - 'BILLING_HOST', 'PLATFORM_HOST' <string> — service host
+  - 'BILLING_PORT', 'PLATFORM_PORT' <int>
+  - 'BILLING_KEY', 'PLATFORM_KEY' <string>`, gonfig.UsageOfEnvs(&Config{}, gonfig.EnvUsageWithPrefix("BILLING", "PLATFORM")))
+
+		var buf bytes.Buffer
+		err := gonfig.New(gonfig.Config{EnvPrefix: "BILLING", EnvPrefixes: []string{"PLATFORM"}, Envs: []string{}, Args: []string{"--help"}, Output: &buf}).Load(&Config{})
+		require.ErrorIs(t, err, pflag.ErrHelp)
+		require.Contains(t, buf.String(), "'BILLING_PORT', 'PLATFORM_PORT' <int>")
+	})
+}
@@ -1,12 +1,14 @@
 package gonfig
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net"
-	"os"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -20,22 +22,98 @@ const (
 	FlagSetName  = "flags" // FlagSetName is name of the flag set for the command-line interface.
 )
 
+// ErrHelp is returned by the loader when the help flag (`--help` or `-h`) is provided.
+// It carries the rendered usage of flags and environment variables, so the caller decides
+// where to print it and whether to terminate the process.
+//
+// ErrHelp wraps pflag.ErrHelp, so `errors.Is(err, pflag.ErrHelp)` reports true for it.
+type ErrHelp struct {
+	Usage string // Usage is the rendered help of flags and environment variables.
+}
+
+// Error returns a short description of the error, the usage itself is available in Usage.
+func (e *ErrHelp) Error() string { return "gonfig: help requested" }
+
+// Unwrap returns pflag.ErrHelp, which is the original error of the flag parser.
+func (e *ErrHelp) Unwrap() error { return pflag.ErrHelp }
+
+// flagsParser is the parser of command-line flags, it contributes values of the provided
+// flags to the layers of the current load.
+type flagsParser struct {
+	args     []string
+	commands bool // flags of commands are accepted and shown in the usage, see WithConfigCommands.
+}
+
 // newFlagsLoader creates a new parser that loads configuration from command-line flags.
 // It uses the provided arguments to populate the configuration by preparing and parsing the flags.
+// When the help flag is provided, the parser returns ErrHelp with the usage of flags instead of printing it.
 // Returns a Parser that processes command-line flags.
 func newFlagsLoader(args []string) Parser {
-	return &parserFunc{name: ParserFlags, call: func(val interface{}) error {
-		set := pflag.NewFlagSet(FlagSetName, pflag.ContinueOnError)
-		if err := PrepareFlags(set, val); err != nil {
-			return err
+	return &flagsParser{args: args}
+}
+
+// Type returns the type of the flags parser.
+func (p *flagsParser) Type() ParserType { return ParserFlags }
+
+// Load loads command-line flags into the destination.
+func (p *flagsParser) Load(dest interface{}) error {
+	return p.LoadContext(context.Background(), dest)
+}
+
+// LoadContext parses command-line flags into a copy of the destination, so the usage shows current
+// values as defaults, and contributes values of the provided flags to the layers of the current load.
+func (p *flagsParser) LoadContext(ctx context.Context, dest interface{}) error {
+	scratch := dest
+	if rv := reflect.ValueOf(dest); rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Struct {
+		clone := reflect.New(rv.Type().Elem())
+		clone.Elem().Set(rv.Elem())
+		scratch = clone.Interface()
+	}
+
+	set := pflag.NewFlagSet(FlagSetName, pflag.ContinueOnError)
+	if err := PrepareFlags(set, scratch); err != nil {
+		return err
+	}
+
+	if p.commands {
+		defineCommands(set, new(configCommands))
+	}
+
+	var buf bytes.Buffer
+	set.SetOutput(&buf)
+
+	if err := set.Parse(p.args); errors.Is(err, pflag.ErrHelp) {
+		return &ErrHelp{Usage: buf.String()}
+	} else if err != nil {
+		return err
+	} else if err = secretFlagError(set); err != nil {
+		return err
+	}
+
+	changed := make(map[string]bool)
+	set.Visit(func(flag *pflag.Flag) { changed[flag.Name] = true })
+
+	tree := make(keyTree)
+	for elem, err := range ReflectFieldsOf(scratch, treeOptions) {
+		if err != nil {
+			return fmt.Errorf("(flags) %w", err)
 		}
 
-		set.SetOutput(os.Stdout)
+		if name := ParseTagOptions(elem.Field.Tag).FlagFullName; name != "" && changed[name] {
+			tree[elem.Path()] = treeValue{
+				value: elem.Value.Interface(),
+				key:   "--" + name,
+				raw:   rawString(elem, set.Lookup(name).Value.String()),
+			}
+		}
+	}
 
-		return set.Parse(args)
-	}}
+	return contribute(ctx, layer{source: ParserFlags, tree: tree}, dest)
 }
 
+// needsDest reports true, current values of the destination are shown in the usage as defaults.
+func (p *flagsParser) needsDest() bool { return true }
+
 // PrepareFlags prepares flags for the given flag set based on the fields of the destination struct.
 // It inspects the struct fields and creates corresponding flags in the flag set using the specified tags.
 // Returns an error if the preparation of flags fails.
@@ -48,7 +126,7 @@ func PrepareFlags(flagSet *pflag.FlagSet, dest any) error {
 		}
 
 		options := ParseTagOptions(elem.Field.Tag)
-		if options.FlagFullName == "" {
+		if options.FlagFullName == "" || options.FlagFullName == "-" {
 			continue
 		}
 
@@ -56,70 +134,186 @@ func PrepareFlags(flagSet *pflag.FlagSet, dest any) error {
 			return fmt.Errorf("(flags) shorthand is more than one ASCII character %q", options.FlagShortName)
 		}
 
-		if err = prepareFlag(flagSet, elem.Value, options); err != nil {
+		if err = prepareFlag(flagSet, secretOf(elem.Value), options); err != nil {
 			return fmt.Errorf("(flags) %w", err)
 		}
+
+		// values of secret flags are hidden in the usage and in errors, see secretFlag.
+		if flag := flagSet.Lookup(options.FlagFullName); isSecret(elem) {
+			if !elem.Value.IsZero() {
+				flag.DefValue = RedactedValue
+			}
+
+			if flag.Value.Type() != "bool" {
+				flag.Value = &secretFlag{Value: flag.Value}
+			}
+		}
 	}
 
 	return nil
 }
 
-// parseConfigPath creates a Parser responsible for handling the "config-path" functionality.
+// ToArgs renders the destination as command-line arguments, so it is the inverse of PrepareFlags:
+// the result can be passed as `Config.Args` (or to a child process) to load the same configuration.
+// Every field with the `flag` tag is rendered as `--name=value`, items of slices are rendered as
+// repeated flags (items of string slices are quoted if needed, like pflag reads them). Nil pointers,
+// empty slices and zero structs (e.g. net.IPNet) have no textual form and are omitted.
+//
+// Values of secret fields are NOT redacted and arguments of processes are visible to other users
+// of the host, so prefer ToEnvs to pass secrets. It returns nil if flags could not be prepared.
+func ToArgs(dest any) []string {
+	scratch := dest
+	if rv := reflect.ValueOf(dest); rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Struct {
+		clone := reflect.New(rv.Type().Elem())
+		clone.Elem().Set(rv.Elem())
+		scratch = clone.Interface()
+	}
+
+	set := pflag.NewFlagSet(FlagSetName, pflag.ContinueOnError)
+	set.SortFlags = false
+
+	if err := PrepareFlags(set, scratch); err != nil {
+		return nil
+	}
+
+	var out []string
+	for elem, err := range ReflectFieldsOf(scratch, treeOptions) {
+		if err != nil {
+			return nil
+		}
+
+		flag := set.Lookup(ParseTagOptions(elem.Field.Tag).FlagFullName)
+		if flag == nil || unsetValue(secretOf(elem.Value)) {
+			continue
+		}
+
+		value := flag.Value
+		if secret, ok := value.(*secretFlag); ok {
+			value = secret.Value
+		}
+
+		slice, ok := value.(pflag.SliceValue)
+		if !ok {
+			out = append(out, "--"+flag.Name+"="+value.String())
+
+			continue
+		}
+
+		for _, item := range slice.GetSlice() {
+			if value.Type() == "stringSlice" {
+				item = csvQuote(item)
+			}
+
+			out = append(out, "--"+flag.Name+"="+item)
+		}
+	}
+
+	return out
+}
+
+// unsetValue reports whether the value has no textual form: it is a nil pointer, slice, map
+// or interface, or a zero struct (e.g. net.IPNet).
+func unsetValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return value.IsNil()
+	case reflect.Struct:
+		return value.IsZero()
+	default:
+		return false
+	}
+}
+
+// csvQuote quotes the item of the string slice if it contains commas, quotes or line breaks,
+// because pflag reads values of string slices as CSV records.
+func csvQuote(item string) string {
+	if !strings.ContainsAny(item, ",\"\r\n") {
+		return item
+	}
+
+	return `"` + strings.ReplaceAll(item, `"`, `""`) + `"`
+}
+
+// configPathParser is responsible for handling the "config-path" functionality.
+// It resolves the path to the configuration file from the command-line arguments, which is then
+// passed to parsers that implement the ParserConfigSetter interface.
+//
+// The path is resolved for every destination separately and is never stored in the parser,
+// so the same parser can be safely used by concurrent loads.
+type configPathParser struct {
+	args []string
+}
+
+// parseConfigPath creates a parser responsible for handling the "config-path" functionality.
 // This parser reflects over the fields of the provided struct and parses flags related to the configuration path.
 // It uses the pflag library to handle command-line flags and extracts flag metadata from struct tags.
 //
 // Parameters:
-// - svc: A pointer to a loader struct that contains the arguments (`svc.Args`) and the config field (`svc.config`) to be populated.
+// - args: The command-line arguments from which the config path will be parsed.
+//
+// Returns:
+// - A parser that is responsible for extracting and validating the "config-path" flag.
+//
+// Example usage:
+//
+//	parser := parseConfigPath(args)
+//	path, err := parser.lookup(configStruct)  // Parses the config path from the struct tags and command-line arguments.
+func parseConfigPath(args []string) *configPathParser {
+	return &configPathParser{args: args}
+}
+
+// Type returns the type of the config-path parser.
+func (p *configPathParser) Type() ParserType { return ParserConfigSet }
+
+// Load validates the "config-path" flag of the destination struct and the command-line arguments.
+// It does not modify the destination, the resolved path is returned by lookup.
+func (p *configPathParser) Load(dest any) error {
+	_, err := p.lookup(dest)
+
+	return err
+}
+
+// lookup resolves the config path for the provided struct.
 //
 // The function performs the following operations:
 // 1. Reflects over the fields of the `val` argument using ReflectFieldsOf, filtering based on `ReflectOptions` (only settable fields are considered).
 // 2. For each field, it checks if the field is tagged with `FlagConfig`, indicating it should be configured from the command line.
 // 3. Ensures that only string fields are used for the configuration path, otherwise an error is returned.
 // 4. Uses pflag to define and parse the configuration flag based on full name and short name from the `TagOptions`.
-// 5. Parses the command-line arguments (`svc.Args`) to populate the `svc.config` field.
+// 5. Parses the command-line arguments to populate the resulting path.
 //
 // If an error occurs during reflection or flag parsing, it returns a formatted error.
-//
-// Returns:
-// - A Parser that is responsible for extracting and validating the "config-path" flag.
-//
-// Example usage:
-//
-//	parser := parseConfigPath(loaderInstance)
-//	err := parser.Parse(configStruct)  // Parses the config path from the struct tags and command-line arguments.
-func parseConfigPath(svc *loader) Parser {
-	return &parserFunc{name: "config-path", call: func(val any) error {
-		flags := pflag.NewFlagSet("config", pflag.ContinueOnError)
-		flags.SetOutput(io.Discard)
-		flags.ParseErrorsWhitelist.UnknownFlags = true
-
-		var path string
-		for elem, err := range ReflectFieldsOf(val, ReflectOptions{CanSet: True()}) {
-			if err != nil {
-				return fmt.Errorf("(config-path) could not fetch config flag: %w", err)
-			}
+func (p *configPathParser) lookup(val any) (string, error) {
+	flags := pflag.NewFlagSet("config", pflag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.ParseErrorsWhitelist.UnknownFlags = true
 
-			var opts TagOptions
-			if opts = ParseTagOptions(elem.Field.Tag); !opts.FlagConfig {
-				continue
-			}
-			if elem.Value.Kind() != reflect.String {
-				return fmt.Errorf("(config-path) expect string, got %q", elem.Value.Kind())
-			}
+	var path string
+	for elem, err := range ReflectFieldsOf(val, ReflectOptions{CanSet: True()}) {
+		if err != nil {
+			return "", fmt.Errorf("(config-path) could not fetch config flag: %w", err)
+		}
 
-			if opts.FlagShortName != "" && opts.FlagShortName != "-" {
-				flags.StringVarP(&svc.config, opts.FlagFullName, opts.FlagShortName, path, opts.FieldUsage)
-			} else {
-				flags.StringVar(&svc.config, opts.FlagFullName, path, opts.FieldUsage)
-			}
+		var opts TagOptions
+		if opts = ParseTagOptions(elem.Field.Tag); !opts.FlagConfig {
+			continue
+		}
+		if elem.Value.Kind() != reflect.String {
+			return "", fmt.Errorf("(config-path) expect string, got %q", elem.Value.Kind())
 		}
 
-		if err := flags.Parse(svc.Args); err != nil && !errors.Is(err, pflag.ErrHelp) {
-			return fmt.Errorf("(config-path) could not parse flags: %w", err)
+		if opts.FlagShortName != "" && opts.FlagShortName != "-" {
+			flags.StringVarP(&path, opts.FlagFullName, opts.FlagShortName, path, opts.FieldUsage)
+		} else {
+			flags.StringVar(&path, opts.FlagFullName, path, opts.FieldUsage)
 		}
+	}
 
-		return nil
-	}}
+	if err := flags.Parse(p.args); err != nil && !errors.Is(err, pflag.ErrHelp) {
+		return "", fmt.Errorf("(config-path) could not parse flags: %w", err)
+	}
+
+	return path, nil
 }
 
 // prepareFlag sets up a flag in the given flag set based on the field's type and the provided struct field information.
@@ -307,3 +307,73 @@ func TestPrepareFlags_Errors(t *testing.T) {
 		}
 	}{}))
 }
+
+func TestToArgs(t *testing.T) {
+	type Config struct {
+		NestedFlagConfig
+
+		Password gonfig.Secret[string] `flag:"password"`
+		Ports    []int                 `flag:"ports"`
+		Network  net.IPNet             `flag:"network"`
+	}
+
+	cfg := Config{
+		Password: gonfig.NewSecret("p@ss"),
+		Ports:    []int{80, 443},
+		Network:  net.IPNet{IP: net.ParseIP("10.0.0.0").To4(), Mask: net.CIDRMask(8, 32)},
+	}
+
+	cfg.Debug = true
+	cfg.Port = 8080
+	cfg.Timeout = 5 * time.Second
+	cfg.Name = "service"
+	cfg.Tags = []string{"a", "b"}
+	cfg.IP = net.ParseIP("127.0.0.1")
+	cfg.IPMask = net.CIDRMask(16, 32)
+	cfg.SkipDash = "skipped"
+
+	args := gonfig.ToArgs(&cfg)
+	require.Equal(t, []string{
+		"--password=p@ss",
+		"--ports=80",
+		"--ports=443",
+		"--network=10.0.0.0/8",
+		"--ip-mask=ffff0000",
+		"--debug=true",
+		"--port=8080",
+		"--timeout=5s",
+		"--name=service",
+		"--tags=a",
+		"--tags=b",
+		"--ip=127.0.0.1",
+	}, args)
+
+	var out Config
+	require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: args}).Load(&out))
+
+	cfg.SkipDash = ""
+	require.Equal(t, cfg, out)
+
+	require.Nil(t, gonfig.ToArgs(cfg))
+
+	t.Run("zero values", func(t *testing.T) {
+		args := gonfig.ToArgs(&Config{})
+		require.Equal(t, []string{"--password=", "--debug=false", "--port=0", "--timeout=0s", "--name="}, args)
+
+		var out Config
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: args}).Load(&out))
+		require.Equal(t, Config{}, out)
+	})
+
+	t.Run("commas", func(t *testing.T) {
+		var in Config
+		in.Tags = []string{"a,b", `say "hi"`, "c"}
+
+		args := gonfig.ToArgs(&in)
+		require.Contains(t, args, `--tags="a,b"`)
+
+		var out Config
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: args}).Load(&out))
+		require.Equal(t, in.Tags, out.Tags)
+	})
+}
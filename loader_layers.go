@@ -0,0 +1,370 @@
+package gonfig
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// keyTree holds configuration values contributed by a single source. The tree is flattened:
+// keys are paths of destination fields built from their Go names (see ReflectValue.Path).
+type keyTree map[string]treeValue
+
+// treeValue is a value of the key tree with its key in the source and the raw input, see FieldSource.
+//
+// Values are either typed, e.g. parsed by pflag or from the "default" tag, and then assigned
+// as is, or raw, e.g. strings of environment variables and values of a MapSource, and then
+// converted into the type of the field by the shared decode hooks (see decodeEnv).
+type treeValue struct {
+	value any
+	key   string
+	raw   string
+}
+
+// layer is a key tree of the source. The tag is used to match keys of raw nested maps,
+// when they are decoded into fields that are maps or pointers to structs.
+type layer struct {
+	source ParserType
+	tag    string
+	tree   keyTree
+}
+
+// layers collects key trees of sources during a single load into the destination.
+// Layers are merged by precedence (every next layer overrides the previous ones) and decoded
+// into the destination once, when they are flushed, see loader.execute.
+type layers struct {
+	loader  *loader
+	dest    any
+	items   []layer
+	done    int                    // number of layers that are already decoded into the destination
+	sources map[string]FieldSource // sources of fields set during the load, see Loader.LoadExplain
+	parsers map[ParserType]Parser  // parsers created for the load, see loader.bootstrap
+}
+
+// layersKey is the context key of the layers of the current load.
+type layersKey struct{}
+
+// treeOptions defines fields of the destination that can be contributed by key trees.
+var treeOptions = ReflectOptions{CanSet: True(), AsField: []reflect.Type{reflect.TypeOf(net.IPNet{})}}
+
+// layeredParser is implemented by parsers that contribute a key tree to the layers of the current
+// load instead of decoding values into the destination, see contribute.
+type layeredParser interface {
+	ContextParser
+
+	// needsDest reports whether the parser reads values already loaded into the destination,
+	// so pending layers must be decoded before it is executed.
+	needsDest() bool
+}
+
+// newLayers creates layers for the load into the provided destination, parsers created for the load
+// (see bootstrap) take precedence over the registered ones.
+func (l *loader) newLayers(dest any, parsers map[ParserType]Parser) *layers {
+	return &layers{loader: l, dest: dest, sources: make(map[string]FieldSource), parsers: parsers}
+}
+
+// withLayers returns the context that carries the layers of the current load.
+func withLayers(ctx context.Context, s *layers) context.Context {
+	return context.WithValue(ctx, layersKey{}, s)
+}
+
+// withoutLayers returns the context that hides the layers of the current load, so parsers
+// called with it decode values into the destination directly.
+func withoutLayers(ctx context.Context) context.Context {
+	return context.WithValue(ctx, layersKey{}, (*layers)(nil))
+}
+
+// layersOf returns the layers of the current load, if the destination is the destination of the load.
+// Otherwise, e.g. the parser is used on its own or is executed in parallel (see WithParallelParsers),
+// it returns nil.
+func layersOf(ctx context.Context, dest any) *layers {
+	if s, ok := ctx.Value(layersKey{}).(*layers); ok && s != nil && s.dest == dest {
+		return s
+	}
+
+	return nil
+}
+
+// contribute adds the key tree of the source to the layers of the current load, or decodes it into
+// the destination, when the parser is used on its own. Nothing is contributed if the context is done.
+func contribute(ctx context.Context, item layer, dest any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if s := layersOf(ctx, dest); s != nil {
+		s.items = append(s.items, item)
+
+		return nil
+	}
+
+	_, err := decodeLayers(dest, []layer{item}, 0, nil)
+
+	return err
+}
+
+// pending reports whether there are layers that are not decoded into the destination yet.
+func (s *layers) pending() bool { return s.done < len(s.items) }
+
+// flush merges pending layers and decodes them into the destination. Decoding errors of values
+// contributed by optional parsers are logged and skipped (see WithOptionalParser).
+func (s *layers) flush(ctx context.Context) error {
+	if !s.pending() {
+		return nil
+	}
+
+	done := s.done
+	s.done = len(s.items)
+
+	sources, err := decodeLayers(s.dest, s.items, done, func(source ParserType, err error) bool {
+		if !s.optional(source) {
+			return false
+		}
+
+		s.loader.logRun(ctx, source, true, 0, err)
+
+		return true
+	})
+
+	for _, source := range sources {
+		s.sources[source.Path] = source
+	}
+
+	return err
+}
+
+// decodeLayers merges layers and decodes merged values into the fields of the destination, layers
+// before done are already decoded, so only fields contributed by the next ones are decoded.
+// By default, every next layer replaces values of the previous ones. Fields with the MergeTag merge
+// values of all layers of the load instead (not the current value of the field, so repeated loads
+// into the same destination do not accumulate), and values of defaults are only used when no other
+// layer contributes the field. When skip is provided and reports true, the decoding error of the
+// value is ignored: the value is not merged, and the value of the previous layer is used instead.
+//
+// It returns sources of decoded fields, the source of the merged field is its last layer.
+func decodeLayers(dest any, items []layer, done int, skip func(source ParserType, err error) bool) ([]FieldSource, error) {
+	merged := make(map[string][]int)
+	for i := range items {
+		for path := range items[i].tree {
+			merged[path] = append(merged[path], i)
+		}
+	}
+
+	var sources []FieldSource
+	for elem, err := range ReflectFieldsOf(dest, treeOptions) {
+		if err != nil {
+			return sources, err
+		}
+
+		strategy, err := mergeStrategy(elem.Field)
+		if err != nil {
+			return sources, err
+		}
+
+		path := elem.Path()
+		if stack := merged[path]; len(stack) == 0 || stack[len(stack)-1] < done {
+			continue
+		}
+
+		if strategy != MergeReplace {
+			var last *layer
+
+			result := reflect.New(elem.Value.Type()).Elem()
+			for _, i := range withoutDefaults(items, merged[path]) {
+				item := &items[i]
+				value := reflect.New(elem.Value.Type()).Elem()
+				if err = decodeValue(value, item.tree[path].value, item.tag); err != nil {
+					err = fmt.Errorf("(%s) could not decode field %q: %w", item.source, path, redactError(elem, err))
+					if skip == nil || !skip(item.source, err) {
+						return sources, err
+					}
+
+					continue
+				}
+
+				mergeValue(result, value, strategy)
+				last = item
+			}
+
+			if last != nil {
+				elem.Value.Set(result)
+				sources = append(sources, last.explain(path))
+			}
+
+			continue
+		}
+
+		for stack := merged[path]; len(stack) > 0 && stack[len(stack)-1] >= done; stack = stack[:len(stack)-1] {
+			item := &items[stack[len(stack)-1]]
+			if err = decodeValue(elem.Value, item.tree[path].value, item.tag); err == nil {
+				sources = append(sources, item.explain(path))
+
+				break
+			}
+
+			err = fmt.Errorf("(%s) could not decode field %q: %w", item.source, path, redactError(elem, err))
+			if skip == nil || !skip(item.source, err) {
+				return sources, err
+			}
+		}
+	}
+
+	return sources, nil
+}
+
+// withoutDefaults returns indexes of layers without layers of defaults, unless only defaults are
+// there: a default is a fallback, so it is not merged with values of other sources.
+func withoutDefaults(items []layer, indexes []int) []int {
+	out := slices.DeleteFunc(slices.Clone(indexes), func(i int) bool { return items[i].source == ParserDefaults })
+	if len(out) == 0 {
+		return indexes
+	}
+
+	return out
+}
+
+// explain returns the source of the field with the provided path contributed by the layer.
+func (l *layer) explain(path string) FieldSource {
+	value := l.tree[path]
+
+	return FieldSource{Path: path, Source: l.source, Key: value.key, Raw: value.raw}
+}
+
+// decodeValue sets the value into the field. Values assignable to the field are set as is,
+// others are decoded by mapstructure with the shared decode hooks (into the wrapped value
+// for a Secret), nil resets the field.
+func decodeValue(field reflect.Value, value any, tag string) error {
+	if value == nil {
+		field.SetZero()
+
+		return nil
+	}
+
+	if val := reflect.ValueOf(value); val.Type().AssignableTo(field.Type()) {
+		field.Set(val)
+
+		return nil
+	}
+
+	if isSecretType(field.Type()) && field.CanAddr() {
+		return decodeValue(secretOf(field), value, tag)
+	}
+
+	out := reflect.New(field.Type())
+	conf := &mapstructure.DecoderConfig{
+		Result:          out.Interface(),
+		TagName:         tag,
+		Squash:          true,
+		SquashTagOption: "squash",
+		DecodeHook:      decodeEnv()}
+	if dec, err := mapstructure.NewDecoder(conf); err != nil {
+		return fmt.Errorf("could not prepare decoder: %w", err)
+	} else if err = dec.Decode(value); err != nil {
+		return err
+	}
+
+	field.Set(out.Elem())
+
+	return nil
+}
+
+// lookupTree resolves the value of the field in the raw nested map. Every owner of the field is
+// a level of nesting, its key is matched against names returned by the names function, exactly
+// and then case-insensitively. Embedded structs without names are squashed into their owners.
+func lookupTree(raw map[string]any, field *ReflectValue, names func(field reflect.StructField) []string) (any, []string, bool) {
+	var chain []*ReflectValue
+	for owner := field; owner != nil && owner.Owner != nil; owner = owner.Owner {
+		chain = append(chain, owner)
+	}
+
+	slices.Reverse(chain)
+
+	var (
+		value any = raw
+		keys  []string
+	)
+
+	for _, item := range chain {
+		candidates := names(item.Field)
+		if len(candidates) == 0 && item.Field.Anonymous {
+			continue
+		}
+
+		level, ok := value.(map[string]any)
+		if !ok {
+			return nil, nil, false
+		}
+
+		var key string
+		if key, value, ok = lookupKey(level, candidates); !ok {
+			return nil, nil, false
+		}
+
+		keys = append(keys, key)
+	}
+
+	return value, keys, true
+}
+
+// lookupKey returns the first key that matches one of the names and its value, keys are matched
+// exactly first and then case-insensitively (in sorted order, so the result is deterministic).
+func lookupKey(level map[string]any, names []string) (string, any, bool) {
+	for _, name := range names {
+		if value, ok := level[name]; ok {
+			return name, value, true
+		}
+	}
+
+	keys := slices.Sorted(maps.Keys(level))
+	for _, name := range names {
+		for _, key := range keys {
+			if strings.EqualFold(key, name) {
+				return key, level[key], true
+			}
+		}
+	}
+
+	return "", nil, false
+}
+
+// tagNames returns a function that returns names of the field for lookupTree: the name from the
+// provided tag (if any) and the Go name. Embedded fields without the tag have no names.
+func tagNames(tag string) func(field reflect.StructField) []string {
+	return func(field reflect.StructField) []string {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		switch {
+		case name == "-":
+			return nil
+		case name != "":
+			return []string{name}
+		case field.Anonymous:
+			return nil
+		default:
+			return []string{field.Name}
+		}
+	}
+}
+
+// rawTree resolves values of all fields of the destination in the raw nested map, fields are matched
+// by the names function (see lookupTree). The key function builds the key of the value in the source
+// from the matched keys of the map.
+func rawTree(raw map[string]any, dest any, names func(field reflect.StructField) []string, key func(keys []string) string) (keyTree, error) {
+	tree := make(keyTree)
+	for elem, err := range ReflectFieldsOf(dest, treeOptions) {
+		if err != nil {
+			return nil, err
+		}
+
+		if value, keys, ok := lookupTree(raw, elem, names); ok {
+			tree[elem.Path()] = treeValue{value: value, key: key(keys), raw: rawString(elem, value)}
+		}
+	}
+
+	return tree, nil
+}
@@ -0,0 +1,154 @@
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// discardHandler is a slog.Handler that drops all records.
+// It is used when no logger is provided, so the loader can log unconditionally.
+type discardHandler struct{}
+
+// Enabled always returns false, so records are never built.
+func (discardHandler) Enabled(context.Context, slog.Level) bool { return false }
+
+// Handle drops the record.
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+
+// WithAttrs returns the same handler, attributes are dropped anyway.
+func (d discardHandler) WithAttrs([]slog.Attr) slog.Handler { return d }
+
+// WithGroup returns the same handler, groups are dropped anyway.
+func (d discardHandler) WithGroup(string) slog.Handler { return d }
+
+// loggerKey is the context key of the logger of the loader, see loggerOf.
+type loggerKey struct{}
+
+// withLogger returns the context that carries the logger of the loader, so parsers that report
+// their decisions (e.g. fallbacks of NewCachedParser) use it instead of the global logger.
+func withLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// loggerOf returns the logger of the loader carried by the context. Parsers used on their own
+// do not have it, so a logger that drops all records is returned.
+func loggerOf(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+
+	return slog.New(discardHandler{})
+}
+
+// WithLogger creates a LoaderOption that enables structured diagnostics of the loading process.
+// The loader emits records for:
+//   - every parser run, with its type and duration (debug level, or error level if it fails,
+//     or warning level if an optional parser fails);
+//   - the config path resolved from the command-line arguments;
+//   - environment variables that match the EnvPrefix but are not used by any field;
+//   - fallbacks to os.Environ and os.Args when Config.Envs or Config.Args are not provided;
+//   - fallbacks of cached parsers to their snapshots, see NewCachedParser;
+//   - validation failures of required fields.
+//
+// Records never contain values of configuration fields, only their names, paths and sources,
+// so secrets can not leak into logs.
+func WithLogger(logger *slog.Logger) LoaderOption {
+	return func(l *loader) error {
+		if logger != nil {
+			l.logger = logger
+		}
+
+		return nil
+	}
+}
+
+// logFallbacks reports the sources the loader falls back to, when they are not provided by the Config.
+func (l *loader) logFallbacks(config Config) {
+	if config.Envs == nil && !l.SkipEnv {
+		l.logger.Debug("gonfig: environment variables are not provided, os.Environ is used")
+	}
+
+	if config.Args == nil && !l.SkipFlags {
+		l.logger.Debug("gonfig: arguments are not provided, os.Args is used")
+	}
+}
+
+// logRun reports the result of the parser run, errors of optional parsers are reported as skipped.
+func (l *loader) logRun(ctx context.Context, typ ParserType, optional bool, took time.Duration, err error) {
+	if errors.Is(err, pflag.ErrHelp) {
+		l.logger.LogAttrs(ctx, slog.LevelDebug, "gonfig: help requested", slog.String("parser", string(typ)))
+
+		return
+	}
+
+	if err != nil && optional {
+		l.logger.LogAttrs(ctx, slog.LevelWarn, "gonfig: optional parser failed, skipped",
+			slog.String("parser", string(typ)), slog.Duration("took", took), slog.Any("error", err))
+
+		return
+	}
+
+	if err != nil {
+		l.logger.LogAttrs(ctx, slog.LevelError, "gonfig: parser failed",
+			slog.String("parser", string(typ)), slog.Duration("took", took), slog.Any("error", err))
+
+		return
+	}
+
+	l.logger.LogAttrs(ctx, slog.LevelDebug, "gonfig: parser loaded",
+		slog.String("parser", string(typ)), slog.Duration("took", took))
+}
+
+// logIgnoredEnvs reports environment variables that match any of prefixes (see Config.EnvPrefixes)
+// but are not resolved by the env layer of the load. Variables of entries of maps (and other values
+// resolved as nested maps) are used when the variable of the map itself is. Without a prefix all
+// process variables would be reported, so nothing is logged in this case.
+func (l *loader) logIgnoredEnvs(ctx context.Context, state *layers) {
+	prefixes := slices.DeleteFunc(l.envPrefixes(), func(prefix string) bool { return prefix == "" })
+	if len(prefixes) == 0 || !l.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	var (
+		known  = make(map[string]bool)
+		nested []string
+	)
+
+	for _, item := range state.items {
+		if item.source != ParserEnv {
+			continue
+		}
+
+		for _, value := range item.tree {
+			known[value.key] = true
+			if _, ok := value.value.(map[string]any); ok {
+				nested = append(nested, value.key+l.envNesting())
+			}
+		}
+	}
+
+	for _, env := range l.Envs {
+		name, _, _ := strings.Cut(env, envPairDelim)
+
+		var matched bool
+		for _, prefix := range prefixes {
+			matched = matched || strings.HasPrefix(name, prefix+envDelimiter)
+		}
+
+		used := known[name] || slices.ContainsFunc(nested, func(prefix string) bool {
+			return len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)], prefix)
+		})
+
+		if !matched || used {
+			continue
+		}
+
+		l.logger.LogAttrs(ctx, slog.LevelDebug, "gonfig: environment variable ignored", slog.String("env", name))
+	}
+}
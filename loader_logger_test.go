@@ -0,0 +1,112 @@
+package gonfig_test
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/im-kulikov/gonfig"
+)
+
+func newTestLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+			switch attr.Key {
+			case slog.TimeKey, "took":
+				return slog.Attr{}
+			default:
+				return attr
+			}
+		},
+	}))
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	type config struct {
+		Address  string `env:"ADDRESS" flag:"address"`
+		Password string `env:"PASSWORD" secret:"true"`
+		Config   string `flag:"config,config:true"`
+		Required string `required:"true"`
+	}
+
+	err := gonfig.New(gonfig.Config{
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_ADDRESS=:8080", "APP_PASSWORD=top-secret", "APP_UNKNOWN=1", "OTHER=2"},
+		Args:      []string{"--config", "config.json"},
+	}, gonfig.WithLogger(nil), gonfig.WithLogger(newTestLogger(&buf))).Load(&config{})
+	require.Error(t, err)
+
+	require.Equal(t, []string{
+		`level=DEBUG msg="gonfig: parser loaded" parser=defaults`,
+		`level=DEBUG msg="gonfig: parser loaded" parser=env`,
+		`level=DEBUG msg="gonfig: config path resolved" path=config.json`,
+		`level=DEBUG msg="gonfig: parser loaded" parser=flags`,
+		`level=DEBUG msg="gonfig: environment variable ignored" env=APP_UNKNOWN`,
+		`level=ERROR msg="gonfig: validation failed" error="missing required fields:\n\t- field ` +
+			"`Required` <string> is required\"",
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+
+	require.NotContains(t, buf.String(), "top-secret")
+}
+
+func TestWithLogger_IgnoredEnvs(t *testing.T) {
+	var buf bytes.Buffer
+
+	type config struct {
+		Port   int
+		Labels map[string]string `env:"LABELS"`
+		Nested struct {
+			Name string
+		}
+	}
+
+	var cfg config
+	require.NoError(t, gonfig.New(gonfig.Config{
+		EnvPrefix: "APP",
+		Envs:      []string{"APP_PORT=8080", "APP_LABELS_A=1", "APP_NESTED_NAME=app", "APP_UNKNOWN=1"},
+		Args:      []string{},
+	}, gonfig.WithLogger(newTestLogger(&buf))).Load(&cfg))
+
+	require.Equal(t, 8080, cfg.Port, "untagged fields are matched by Go names")
+	require.Equal(t, map[string]string{"A": "1"}, cfg.Labels)
+	require.Equal(t, "app", cfg.Nested.Name)
+
+	require.Contains(t, buf.String(), `msg="gonfig: environment variable ignored" env=APP_UNKNOWN`)
+	for _, name := range []string{"APP_PORT", "APP_LABELS_A", "APP_NESTED_NAME"} {
+		require.NotContains(t, buf.String(), "env="+name+"\n")
+	}
+}
+
+func TestWithLogger_Failures(t *testing.T) {
+	var buf bytes.Buffer
+
+	require.Error(t, gonfig.New(gonfig.Config{SkipDefaults: true, SkipEnv: true, Args: []string{"--unknown"}},
+		gonfig.WithLogger(newTestLogger(&buf))).Load(&struct{}{}))
+
+	require.Equal(t, []string{
+		`level=ERROR msg="gonfig: parser failed" parser=flags error="unknown flag: --unknown"`,
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+
+	buf.Reset()
+
+	var help *gonfig.ErrHelp
+	require.ErrorAs(t, gonfig.New(gonfig.Config{SkipEnv: true, Args: []string{"--help"}},
+		gonfig.WithLogger(newTestLogger(&buf))).Load(&struct{}{}), &help)
+
+	require.Equal(t, []string{
+		`level=DEBUG msg="gonfig: parser loaded" parser=defaults`,
+		`level=DEBUG msg="gonfig: help requested" parser=flags`,
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+
+	buf.Reset()
+
+	require.NoError(t, gonfig.New(gonfig.Config{SkipFlags: true}, gonfig.WithLogger(newTestLogger(&buf))).Load(&struct{}{}))
+	require.Contains(t, buf.String(), `level=DEBUG msg="gonfig: environment variables are not provided, os.Environ is used"`)
+	require.NotContains(t, buf.String(), "os.Args")
+}
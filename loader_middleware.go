@@ -0,0 +1,121 @@
+package gonfig
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ParserMiddleware is a function type that wraps a Parser to add behavior around its loading,
+// such as logging, timing or auditing. The middleware receives the next Parser in the chain and
+// returns a Parser that is used by the loader instead.
+//
+// The returned Parser should keep the type of the next one. To keep deadlines and cancellation
+// working, it should also implement the ContextParser interface when the next one does.
+//
+// Example usage:
+//
+//	func audit(next gonfig.Parser) gonfig.Parser {
+//	    return gonfig.NewCustomParser(next.Type(), func(dest interface{}) error {
+//	        log.Printf("loading %s", next.Type())
+//	        return next.Load(dest)
+//	    })
+//	}
+type ParserMiddleware func(next Parser) Parser
+
+// BeforeLoadHook is called by the loader right before a parser is executed.
+// It receives the type of the parser and the destination object.
+type BeforeLoadHook func(typ ParserType, dest any)
+
+// AfterLoadHook is called by the loader right after a parser is executed.
+// It receives the type of the parser, the destination object, the duration of the run and its error.
+// Values of the parser are already in the destination, even if the parser contributes them to the layers
+// of the load (e.g. ParserEnv), so the hook can audit every source as it loads.
+type AfterLoadHook func(typ ParserType, dest any, took time.Duration, err error)
+
+// WithParserMiddleware creates a LoaderOption that wraps every parser executed by the loader,
+// both built-in (ParserDefaults, ParserEnv, ParserFlags) and custom ones, with the provided
+// middlewares. The first middleware is the outermost one. ParserConfigSet only resolves the
+// config path and does not load anything into the destination, so it is not wrapped.
+//
+// Middlewares are applied once, when the loader is built, after all other options.
+// Parsers implementing ParserConfigSetter still receive the config path, it is set to the
+// original parser before the wrapped one is executed.
+func WithParserMiddleware(middlewares ...ParserMiddleware) LoaderOption {
+	return func(l *loader) error {
+		l.middlewares = append(l.middlewares, middlewares...)
+
+		return nil
+	}
+}
+
+// WithBeforeLoad creates a LoaderOption that registers a hook called before every parser run.
+// Hooks are called in the order of registration.
+func WithBeforeLoad(hook BeforeLoadHook) LoaderOption {
+	return func(l *loader) error {
+		if hook != nil {
+			l.before = append(l.before, hook)
+		}
+
+		return nil
+	}
+}
+
+// WithAfterLoad creates a LoaderOption that registers a hook called after every parser run,
+// including failed ones. Hooks are called in the order of registration.
+func WithAfterLoad(hook AfterLoadHook) LoaderOption {
+	return func(l *loader) error {
+		if hook != nil {
+			l.after = append(l.after, hook)
+		}
+
+		return nil
+	}
+}
+
+// wrapParsers applies registered middlewares to all parsers in the sequence, except ParserConfigSet.
+// The original parsers are kept in `groups`, so the loader can still set the config path to them.
+func (l *loader) wrapParsers() {
+	l.wrapped = make(map[ParserType]Parser, len(l.sequence))
+	for _, typ := range l.sequence {
+		if typ == ParserConfigSet {
+			l.wrapped[typ] = l.groups[typ]
+
+			continue
+		}
+
+		l.wrapped[typ] = l.wrap(l.groups[typ])
+	}
+}
+
+// wrap applies registered middlewares to the parser, the first middleware is the outermost one.
+// Layered parsers are wrapped into layeredLoad first, so they keep the layers of the load.
+func (l *loader) wrap(parser Parser) Parser {
+	if layered, ok := parser.(layeredParser); ok && len(l.middlewares) > 0 {
+		parser = &layeredLoad{layeredParser: layered, running: &l.running}
+	}
+
+	for i := len(l.middlewares) - 1; i >= 0; i-- {
+		parser = l.middlewares[i](parser)
+	}
+
+	return parser
+}
+
+// layeredLoad restores the context of the load for a layered parser wrapped with middlewares that
+// call Load instead of LoadContext, so the parser still contributes its values to the layers of the
+// load (and they are explained by keys and raw values) instead of decoding them into the destination.
+type layeredLoad struct {
+	layeredParser
+
+	running *sync.Map // contexts of running parsers by destination, see loader.run.
+}
+
+// Load loads values with the context of the parser running for the destination, if any.
+func (p *layeredLoad) Load(dest interface{}) error {
+	if ctx, ok := p.running.Load(dest); ok {
+		return p.LoadContext(ctx.(context.Context), dest)
+	}
+
+	return p.layeredParser.Load(dest)
+}
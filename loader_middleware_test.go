@@ -0,0 +1,137 @@
+package gonfig_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/im-kulikov/gonfig"
+)
+
+type middlewareParser struct {
+	gonfig.Parser
+
+	name  string
+	calls *[]string
+}
+
+func (m *middlewareParser) Load(dest interface{}) error {
+	*m.calls = append(*m.calls, m.name+":"+string(m.Type()))
+
+	return m.Parser.Load(dest)
+}
+
+func TestParserMiddleware(t *testing.T) {
+	var calls []string
+
+	middleware := func(name string) gonfig.ParserMiddleware {
+		return func(next gonfig.Parser) gonfig.Parser {
+			return &middlewareParser{Parser: next, name: name, calls: &calls}
+		}
+	}
+
+	var cfg CustomLoaderConfig
+	require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{"--config", "", "--int-field", "5"}},
+		gonfig.WithCustomParser(&customJSONParser{}),
+		gonfig.WithParserMiddleware(middleware("outer"), middleware("inner"))).Load(&cfg))
+
+	require.Equal(t, []string{
+		"outer:defaults", "inner:defaults",
+		"outer:env", "inner:env",
+		"outer:json", "inner:json",
+		"outer:flags", "inner:flags",
+	}, calls)
+
+	require.Equal(t, 5, cfg.FieldInt)
+	require.Equal(t, "default-value", cfg.FieldString)
+}
+
+func TestParserMiddleware_Explain(t *testing.T) {
+	var calls []string
+
+	loader := gonfig.New(gonfig.Config{Envs: []string{"APP_LEVEL=debug"}, Args: []string{"--address", ":9090"}, EnvPrefix: "APP"},
+		gonfig.WithMapSource(&mapSource{name: "file", values: map[string]any{"name": "app"}}),
+		gonfig.WithParserMiddleware(func(next gonfig.Parser) gonfig.Parser {
+			return &middlewareParser{Parser: next, name: "plain", calls: &calls}
+		}))
+
+	var cfg ExplainConfig
+	sources, err := loader.LoadExplain(context.Background(), &cfg)
+	require.NoError(t, err)
+	require.Equal(t, []string{"plain:defaults", "plain:env", "plain:file", "plain:flags"}, calls)
+
+	require.Equal(t, []gonfig.FieldSource{
+		{Path: "Address", Source: gonfig.ParserFlags, Key: "--address", Raw: ":9090"},
+		{Path: "Level", Source: gonfig.ParserEnv, Key: "APP_LEVEL", Raw: "debug"},
+		{Path: "Name", Source: "file", Key: "name", Raw: "app"},
+		{Path: "Database.MaxConns", Source: gonfig.ParserDefaults, Raw: "10"},
+	}, sources, "layered parsers are explained by their layers behind plain middlewares")
+}
+
+func TestLoadHooks(t *testing.T) {
+	type event struct {
+		Type  gonfig.ParserType
+		After bool
+		Err   error
+	}
+
+	var (
+		events []event
+		failed = errors.New("failed")
+	)
+
+	parser := gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}, SkipDefaults: true},
+		gonfig.WithCustomParser(gonfig.NewCustomContextParser(parserCustomType, func(context.Context, any) error {
+			time.Sleep(time.Millisecond)
+
+			return failed
+		})),
+		gonfig.WithBeforeLoad(nil),
+		gonfig.WithAfterLoad(nil),
+		gonfig.WithBeforeLoad(func(typ gonfig.ParserType, dest any) {
+			require.IsType(t, &CustomLoaderConfig{}, dest)
+
+			events = append(events, event{Type: typ})
+		}),
+		gonfig.WithAfterLoad(func(typ gonfig.ParserType, dest any, took time.Duration, err error) {
+			require.IsType(t, &CustomLoaderConfig{}, dest)
+
+			if typ == parserCustomType {
+				require.GreaterOrEqual(t, took, time.Millisecond)
+			}
+
+			events = append(events, event{Type: typ, After: true, Err: err})
+		}))
+
+	require.ErrorIs(t, parser.Load(&CustomLoaderConfig{}), failed)
+	require.Equal(t, []event{
+		{Type: gonfig.ParserEnv},
+		{Type: gonfig.ParserEnv, After: true},
+		{Type: parserCustomType},
+		{Type: parserCustomType, After: true, Err: failed},
+	}, events)
+}
+
+func TestLoadHooks_Values(t *testing.T) {
+	type config struct {
+		Name  string `env:"NAME" flag:"name" default:"default"`
+		Count int    `env:"COUNT" default:"1"`
+	}
+
+	var before, after []string
+	require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{"NAME=env"}, Args: []string{"--name", "flag"}},
+		gonfig.WithBeforeLoad(func(typ gonfig.ParserType, dest any) {
+			before = append(before, string(typ)+"="+dest.(*config).Name)
+		}),
+		gonfig.WithAfterLoad(func(typ gonfig.ParserType, dest any, _ time.Duration, _ error) {
+			after = append(after, string(typ)+"="+dest.(*config).Name)
+		})).Load(&config{}))
+
+	require.Equal(t, []string{"defaults=", "env=default", "flags=env"}, before,
+		"before hooks see values of the previous parsers")
+	require.Equal(t, []string{"defaults=default", "env=env", "flags=flag"}, after,
+		"after hooks see values of the parser in the destination")
+}
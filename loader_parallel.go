@@ -0,0 +1,264 @@
+package gonfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// WithParallelParsers creates a LoaderOption that marks parsers of the provided types as independent,
+// so they can be executed concurrently. It is useful for remote sources (e.g. Consul, Vault, S3),
+// where the startup latency would otherwise be the sum of their round trips.
+//
+// Adjacent independent parsers in the order of the loader are executed as a group:
+//
+//  1. Every parser of the group loads into its own copy of the destination taken before the group
+//     (a layer), so the parsers see values of the previous parsers, but not values of each other.
+//  2. When all parsers are done, fields that every layer changed compared with the copy (including
+//     the ones reset to zero values) are merged into the destination in the order of the loader,
+//     so the result is the same as if parsers were executed one after another.
+//  3. Errors of all parsers of the group are joined (see errors.Join), optional parsers are
+//     skipped as usual (see WithOptionalParser).
+//
+// Hooks (see WithBeforeLoad and WithAfterLoad) receive the layer instead of the destination.
+// ParserConfigSet only resolves the config path, so it can not be executed in parallel.
+//
+// Example usage:
+//
+//	gonfig.New(gonfig.Config{},
+//	    gonfig.WithCustomParser(consulParser),
+//	    gonfig.WithCustomParser(vaultParser),
+//	    gonfig.WithParallelParsers("consul", "vault"))
+func WithParallelParsers(types ...ParserType) LoaderOption {
+	return func(l *loader) error {
+		for _, typ := range types {
+			if typ == ParserConfigSet {
+				return fmt.Errorf("parser %q can not be executed in parallel", typ)
+			}
+
+			l.parallel[typ] = true
+		}
+
+		return nil
+	}
+}
+
+// executeParallel runs the group of independent parsers concurrently, each into its own copy of
+// the destination, and then merges changes of layers into the destination in the order of the group.
+func (l *loader) executeParallel(ctx context.Context, state *layers, group []ParserType, path string, v any) error {
+	// layers are merged into the destination directly, so pending layers must be decoded first.
+	if err := state.flush(ctx); err != nil {
+		return fmt.Errorf("gonfig: could not load: %w", err)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return fmt.Errorf("gonfig: could not load: %w, got %q", ErrExpectPointer, rv.Kind())
+	}
+
+	if rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("gonfig: could not load: %w, got %q", ErrExpectStruct, rv.Elem().Kind())
+	}
+
+	var (
+		wg     sync.WaitGroup
+		base   = reflect.New(rv.Type().Elem())
+		layers = make([]reflect.Value, len(group))
+		errs   = make([]error, len(group))
+		locked bool
+	)
+
+	// every layer is a copy of the destination before the group, changes are detected against it.
+	base.Elem().Set(cloneValue(rv.Elem()))
+
+	for i, typ := range group {
+		parser, wrapped, ok := l.resolve(typ, state.parsers)
+		if !ok {
+			continue
+		}
+
+		// config path is set into the parser right before the load, so it must be kept
+		// until the whole group is done, see loader.setter.
+		if setter, ok := parser.(ParserConfigSetter); ok {
+			if !locked {
+				l.setter.Lock()
+				locked = true
+			}
+
+			setter.SetConfigPath(path)
+		}
+
+		layers[i] = reflect.New(rv.Type().Elem())
+		layers[i].Elem().Set(cloneValue(base.Elem()))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			errs[i] = l.run(ctx, state, typ, wrapped, layers[i].Interface())
+		}()
+	}
+
+	wg.Wait()
+
+	if locked {
+		l.setter.Unlock()
+	}
+
+	var failures []error
+	for i, typ := range group {
+		if err := l.failure(ctx, state, typ, errs[i]); err != nil {
+			failures = append(failures, err)
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.Join(failures...)
+	}
+
+	if err := state.resetMerged(base.Interface(), layers); err != nil {
+		return fmt.Errorf("gonfig: could not load: %w", err)
+	}
+
+	for i, layer := range layers {
+		if layer.IsValid() {
+			before := snapshot(v)
+			mergeLayer(rv.Elem(), layer.Elem(), base.Elem(), MergeReplace)
+			state.record(group[i], before)
+		}
+	}
+
+	return nil
+}
+
+// resetMerged resets fields with the MergeTag that are changed by the layers compared with the base,
+// unless they are already set during the load by a source other than defaults. So merged values start
+// from values of the current load, as they do for key trees (see decodeLayers).
+func (s *layers) resetMerged(base any, layers []reflect.Value) error {
+	contributed := make(map[string]bool)
+	for _, layer := range layers {
+		if !layer.IsValid() {
+			continue
+		}
+
+		for _, change := range Diff(base, layer.Interface()) {
+			contributed[change.Path] = true
+		}
+	}
+
+	for elem, err := range ReflectFieldsOf(s.dest, treeOptions) {
+		if err != nil {
+			return err
+		}
+
+		path := elem.Path()
+		if strategy, _ := mergeStrategy(elem.Field); strategy == MergeReplace || !contributed[path] {
+			continue
+		}
+
+		if source, ok := s.sources[path]; !ok || source.Source == ParserDefaults {
+			elem.Value.SetZero()
+		}
+	}
+
+	return nil
+}
+
+// mergeLayer copies values of the layer that differ from the base (the value the layer was copied from)
+// into the destination. Structs with exported fields only (and pointers to them) are merged field by field,
+// other values are copied as a whole or merged with the strategy of the field (see MergeTag).
+func mergeLayer(dst, src, base reflect.Value, strategy string) {
+	switch {
+	case src.Kind() == reflect.Struct && mergeable(src.Type()):
+		for i := range src.NumField() {
+			strategy, _ := mergeStrategy(src.Type().Field(i))
+			mergeLayer(dst.Field(i), src.Field(i), base.Field(i), strategy)
+		}
+	case src.Kind() == reflect.Ptr && !src.IsNil() && src.Elem().Kind() == reflect.Struct && mergeable(src.Type().Elem()):
+		if dst.IsNil() {
+			dst.Set(reflect.New(src.Type().Elem()))
+		}
+
+		if base.IsNil() {
+			base = reflect.New(src.Type().Elem())
+		}
+
+		mergeLayer(dst.Elem(), src.Elem(), base.Elem(), MergeReplace)
+	case !reflect.DeepEqual(src.Interface(), base.Interface()):
+		mergeValue(dst, src, strategy)
+	}
+}
+
+// cloneValue returns a copy of the value that does not share pointers to structs, maps and slices
+// with the original, so parsers executed in parallel can modify their copies of the destination.
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+
+		for i := range v.NumField() {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(cloneValue(v.Field(i)))
+			}
+		}
+
+		return out
+	case reflect.Ptr:
+		if v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return v
+		}
+
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(cloneValue(v.Elem()))
+
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		out := reflect.New(v.Type()).Elem()
+		out.Set(cloneValue(v.Elem()))
+
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			out.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+		}
+
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(cloneValue(v.Index(i)))
+		}
+
+		return out
+	default:
+		return v
+	}
+}
+
+// mergeable reports whether all fields of the struct type are exported, so it can be merged
+// field by field. Structs like time.Time keep their state in unexported fields and are copied as a whole.
+func mergeable(typ reflect.Type) bool {
+	for i := range typ.NumField() {
+		if !typ.Field(i).IsExported() {
+			return false
+		}
+	}
+
+	return true
+}
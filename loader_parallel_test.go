@@ -0,0 +1,188 @@
+package gonfig_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/im-kulikov/gonfig"
+)
+
+type ParallelConfig struct {
+	Address string `default:"localhost"`
+	Token   string
+	Timeout time.Duration `default:"5s"`
+
+	Remote struct {
+		Consul string
+		Vault  string
+	}
+
+	Started time.Time
+}
+
+// barrierParser returns a parser that waits until all parsers of the barrier are started,
+// so the test fails if parsers are executed sequentially.
+func barrierParser(typ gonfig.ParserType, barrier *sync.WaitGroup, fn func(*ParallelConfig) error) gonfig.Parser {
+	return gonfig.NewCustomParser(typ, func(dest any) error {
+		barrier.Done()
+
+		done := make(chan struct{})
+		go func() { barrier.Wait(); close(done) }()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			return errors.New("parsers are not executed concurrently")
+		}
+
+		return fn(dest.(*ParallelConfig))
+	})
+}
+
+func TestParallelParsers(t *testing.T) {
+	t.Run("merged in order", func(t *testing.T) {
+		var barrier sync.WaitGroup
+		barrier.Add(3)
+
+		started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		var cfg ParallelConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+			gonfig.WithCustomParser(barrierParser("consul", &barrier, func(c *ParallelConfig) error {
+				c.Address, c.Remote.Consul = "consul:8500", "consul"
+				return nil
+			})),
+			gonfig.WithCustomParser(barrierParser("vault", &barrier, func(c *ParallelConfig) error {
+				c.Token, c.Remote.Vault, c.Started = "token", "vault", started
+				return nil
+			})),
+			gonfig.WithCustomParser(barrierParser("s3", &barrier, func(c *ParallelConfig) error {
+				c.Address = "s3-address"
+				return nil
+			})),
+			gonfig.WithParallelParsers("consul", "vault", "s3")).Load(&cfg))
+
+		require.Equal(t, "s3-address", cfg.Address)
+		require.Equal(t, "token", cfg.Token)
+		require.Equal(t, 5*time.Second, cfg.Timeout)
+		require.Equal(t, "consul", cfg.Remote.Consul)
+		require.Equal(t, "vault", cfg.Remote.Vault)
+		require.Equal(t, started, cfg.Started)
+	})
+
+	t.Run("flags override", func(t *testing.T) {
+		type config struct {
+			Address string `flag:"address"`
+		}
+
+		var cfg config
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{"--address", "flag"}},
+			gonfig.WithCustomParser(gonfig.NewCustomParser("consul", func(dest any) error {
+				dest.(*config).Address = "consul"
+				return nil
+			})),
+			gonfig.WithParallelParsers("consul")).Load(&cfg))
+
+		require.Equal(t, "flag", cfg.Address)
+	})
+
+	t.Run("aggregated errors", func(t *testing.T) {
+		consul, vault := errors.New("consul is down"), errors.New("vault is down")
+
+		cfg := ParallelConfig{Token: "untouched"}
+		err := gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+			gonfig.WithCustomParser(gonfig.NewCustomParser("consul", func(any) error { return consul })),
+			gonfig.WithCustomParser(gonfig.NewCustomParser("vault", func(any) error { return vault })),
+			gonfig.WithCustomParser(gonfig.NewCustomParser("s3", func(dest any) error {
+				dest.(*ParallelConfig).Token = "s3"
+				return nil
+			})),
+			gonfig.WithParallelParsers("consul", "vault", "s3")).Load(&cfg)
+
+		require.ErrorIs(t, err, consul)
+		require.ErrorIs(t, err, vault)
+		require.EqualError(t, err, "gonfig: could not load: consul is down\ngonfig: could not load: vault is down")
+		require.Equal(t, "untouched", cfg.Token)
+	})
+
+	t.Run("optional", func(t *testing.T) {
+		var cfg ParallelConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+			gonfig.WithOptionalParser(gonfig.NewCustomParser("consul", func(any) error { return errors.New("down") })),
+			gonfig.WithCustomParser(gonfig.NewCustomParser("vault", func(dest any) error {
+				dest.(*ParallelConfig).Token = "vault"
+				return nil
+			})),
+			gonfig.WithParallelParsers("consul", "vault")).Load(&cfg))
+
+		require.Equal(t, "vault", cfg.Token)
+	})
+
+	t.Run("config path", func(t *testing.T) {
+		file, err := os.CreateTemp(t.TempDir(), "config.json")
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(file).Encode(CustomLoaderConfig{FieldString: "json-value"}))
+		require.NoError(t, file.Close())
+
+		var cfg CustomLoaderConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{"--config", file.Name()}},
+			gonfig.WithCustomParser(&customJSONParser{}),
+			gonfig.WithParallelParsers("json")).Load(&cfg))
+
+		require.Equal(t, "json-value", cfg.FieldString)
+		require.Equal(t, file.Name(), cfg.Config)
+	})
+}
+
+func TestParallelParsers_Changes(t *testing.T) {
+	type config struct {
+		Debug  bool              `default:"true"`
+		Level  string            `env:"LEVEL"`
+		Labels map[string]string `env:"LABELS"`
+	}
+
+	parsers := func() []gonfig.LoaderOption {
+		return []gonfig.LoaderOption{
+			gonfig.WithCustomParser(gonfig.NewCustomParser("a", func(dest any) error {
+				dest.(*config).Debug = false
+				dest.(*config).Labels["a"] = "1"
+				return nil
+			})),
+			gonfig.WithCustomParser(gonfig.NewCustomParser("b", func(dest any) error {
+				dest.(*config).Level = ""
+				dest.(*config).Labels["b"] = "2"
+				return nil
+			})),
+		}
+	}
+
+	envs := []string{"LEVEL=info", "LABELS_env=prod"}
+
+	var sequential config
+	require.NoError(t, gonfig.New(gonfig.Config{Envs: envs, Args: []string{}}, parsers()...).Load(&sequential))
+
+	var parallel config
+	require.NoError(t, gonfig.New(gonfig.Config{Envs: envs, Args: []string{}},
+		append(parsers(), gonfig.WithParallelParsers("a", "b"))...).Load(&parallel))
+
+	require.Equal(t, config{Labels: map[string]string{"env": "prod", "a": "1", "b": "2"}}, sequential)
+	require.Equal(t, config{Labels: map[string]string{"env": "prod", "b": "2"}}, parallel,
+		"zero values are merged, parsers do not see changes of each other")
+}
+
+func TestParallelParsers_Errors(t *testing.T) {
+	_, err := gonfig.NewE(gonfig.Config{}, gonfig.WithParallelParsers(gonfig.ParserConfigSet))
+	require.EqualError(t, err, `gonfig: could not init option: parser "config-setter" can not be executed in parallel`)
+
+	parser := gonfig.New(gonfig.Config{SkipDefaults: true, SkipEnv: true, SkipFlags: true},
+		gonfig.WithCustomParser(gonfig.NewCustomParser("consul", func(any) error { return nil })),
+		gonfig.WithParallelParsers("consul"))
+
+	require.EqualError(t, parser.Load(&[]string{}), `gonfig: could not load: expect struct field, got "slice"`)
+}
@@ -48,25 +48,10 @@ func ValidateRequiredFields(input any) error {
 			continue
 		}
 
-		var path string
-		for owner := elem; owner != nil; owner = owner.Owner {
-			if owner.Field.Name == "" {
-				continue
-			}
-
-			if path == "" {
-				path = owner.Field.Name
-
-				continue
-			}
-
-			path = fmt.Sprintf("%s.%s", owner.Field.Name, path)
-		}
-
 		missingFields = append(missingFields, ErrMissingField{
 			Field: elem.Field.Name,
 			Type:  elem.Field.Type.String(),
-			Path:  path,
+			Path:  elem.Path(),
 		})
 	}
 
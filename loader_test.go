@@ -1,16 +1,21 @@
 package gonfig_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
 
 	"github.com/im-kulikov/gonfig"
@@ -202,3 +207,292 @@ func TestLoader(t *testing.T) {
 		})).Load(&struct{}{}), "gonfig: could not init option: could not init options: expect struct field")
 
 }
+
+func TestLoaderOrder(t *testing.T) {
+	type config struct {
+		Field string `env:"FIELD" flag:"field" default:"default-value"`
+	}
+
+	custom := gonfig.WithCustomParser(gonfig.NewCustomParser(parserCustomType, func(dest any) error {
+		dest.(*config).Field = "custom-value"
+
+		return nil
+	}))
+
+	envs := []string{"FIELD=env-value"}
+	args := []string{"--field", "flag-value"}
+
+	cases := []struct {
+		name   string
+		order  []gonfig.ParserType
+		expect string
+	}{
+		{
+			name:   "default order",
+			expect: "flag-value",
+		},
+		{
+			name:   "env overrides flags",
+			order:  []gonfig.ParserType{gonfig.ParserDefaults, parserCustomType, gonfig.ParserFlags, gonfig.ParserEnv},
+			expect: "env-value",
+		},
+		{
+			name:   "custom overrides env",
+			order:  []gonfig.ParserType{gonfig.ParserConfigSet, gonfig.ParserFlags, gonfig.ParserEnv, parserCustomType, gonfig.ParserDefaults},
+			expect: "custom-value",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config
+			require.NoError(t, gonfig.New(gonfig.Config{Envs: envs, Args: args, LoaderOrder: tt.order}, custom).Load(&cfg))
+			require.Equal(t, tt.expect, cfg.Field)
+
+			cfg = config{}
+			require.NoError(t, gonfig.New(gonfig.Config{Envs: envs, Args: args}, custom, gonfig.WithOrder(tt.order...)).Load(&cfg))
+			require.Equal(t, tt.expect, cfg.Field)
+		})
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		require.EqualError(t, gonfig.New(gonfig.Config{SkipFlags: true},
+			gonfig.WithOrder(gonfig.ParserDefaults, gonfig.ParserEnv, gonfig.ParserFlags)).Load(&config{}),
+			`gonfig: could not prepare order: unknown parser "flags"`)
+
+		require.EqualError(t, gonfig.New(gonfig.Config{SkipFlags: true},
+			gonfig.WithOrder(gonfig.ParserDefaults, gonfig.ParserEnv, gonfig.ParserDefaults)).Load(&config{}),
+			`gonfig: could not prepare order: parser "defaults" listed more than once`)
+
+		require.EqualError(t, gonfig.New(gonfig.Config{SkipFlags: true},
+			gonfig.WithOrder(gonfig.ParserDefaults)).Load(&config{}),
+			`gonfig: could not prepare order: parser "env" is not listed`)
+	})
+}
+
+func TestNewE(t *testing.T) {
+	_, err := gonfig.NewE(gonfig.Config{}, gonfig.WithOptions(nil))
+	require.EqualError(t, err, "gonfig: could not init option: invalid options type: <nil>")
+
+	_, err = gonfig.NewE(gonfig.Config{SkipFlags: true}, gonfig.WithOrder(gonfig.ParserFlags))
+	require.EqualError(t, err, `gonfig: could not prepare order: unknown parser "flags"`)
+
+	var calls int
+	parser, err := gonfig.NewE(gonfig.Config{Envs: []string{}, Args: []string{}},
+		gonfig.WithCustomParser(gonfig.NewCustomParser(parserCustomType, func(any) error {
+			calls++
+
+			return nil
+		})))
+	require.NoError(t, err)
+
+	for range 3 {
+		require.NoError(t, parser.Load(&struct{}{}))
+	}
+
+	require.Equal(t, 3, calls, "custom parser should be called once per load")
+}
+
+func TestNew_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+
+	paths := make([]string, 8)
+	for i := range paths {
+		paths[i] = filepath.Join(dir, strconv.Itoa(i)+".json")
+
+		data, err := json.Marshal(CustomLoaderConfig{FieldString: "json-" + strconv.Itoa(i)})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(paths[i], data, 0o600))
+	}
+
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			parser := gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{"--config", path}},
+				gonfig.WithCustomParser(&customJSONParser{}))
+
+			for range 4 {
+				var cfg CustomLoaderConfig
+				require.NoError(t, parser.Load(&cfg))
+				require.Equal(t, "json-"+strconv.Itoa(i), cfg.FieldString)
+				require.Equal(t, path, cfg.Config)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// shared loader with different destinations
+	shared := gonfig.New(gonfig.Config{Envs: []string{"INT_VALUE=10"}, Args: []string{"--string-field", "flag"}},
+		gonfig.WithCustomParser(new(JSONParser)))
+
+	for range 8 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			var cfg TestLoaderConfig
+			require.NoError(t, shared.Load(&cfg))
+			require.Equal(t, "flag", cfg.StringField)
+			require.Equal(t, 10, cfg.IntField)
+			require.Equal(t, 30*time.Second, cfg.Timeout)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestLoadContext(t *testing.T) {
+	const parserRemote gonfig.ParserType = "remote"
+
+	remote := gonfig.NewCustomContextParser(parserRemote, func(ctx context.Context, dest any) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return nil
+		}
+	})
+
+	t.Run("per-parser timeout", func(t *testing.T) {
+		parser := gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+			gonfig.WithCustomParser(remote),
+			gonfig.WithParserTimeout(parserRemote, time.Millisecond))
+
+		err := parser.LoadContext(context.Background(), &struct{}{})
+		require.EqualError(t, err, `gonfig: could not load: parser "remote" timed out: context deadline exceeded`)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		require.ErrorIs(t, parser.Load(&struct{}{}), context.DeadlineExceeded)
+	})
+
+	t.Run("context deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		require.EqualError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+			gonfig.WithCustomParser(remote)).LoadContext(ctx, &struct{}{}),
+			`gonfig: could not load: parser "remote" timed out: context deadline exceeded`)
+	})
+
+	t.Run("cancelled before plain parser", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.EqualError(t, gonfig.New(gonfig.Config{}).LoadContext(ctx, &struct{}{}),
+			`gonfig: could not load: parser "defaults" cancelled: context canceled`)
+	})
+
+	t.Run("context parser without deadline", func(t *testing.T) {
+		var called bool
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+			gonfig.WithCustomParser(gonfig.NewCustomContextParser(parserRemote, func(ctx context.Context, _ any) error {
+				called = true
+
+				return ctx.Err()
+			}))).LoadContext(context.Background(), &struct{}{}))
+		require.True(t, called)
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		_, err := gonfig.NewE(gonfig.Config{}, gonfig.WithParserTimeout(parserRemote, 0))
+		require.EqualError(t, err, `gonfig: could not init option: invalid timeout 0s for parser "remote"`)
+	})
+}
+
+func TestHelp(t *testing.T) {
+	expectedUsage := `Usage of flags:
+      --int-value int         int value
+      --json-config string    
+      --string-field string    (default "default_value")
+
+Environment variables:
+  - 'TEST_INT_VALUE' <int> — int value
+  - 'TEST_TIMEOUT' <time.Duration> — timeout value (default: 30s)
+  - 'TEST_EMBED_INT_FIELD' <int> — int field (default: 1)
+`
+
+	config := gonfig.Config{Args: []string{"--help"}, Envs: []string{}, EnvPrefix: "TEST"}
+
+	t.Run("returns usage", func(t *testing.T) {
+		err := gonfig.New(config).Load(&TestLoaderConfig{})
+
+		var help *gonfig.ErrHelp
+		require.ErrorAs(t, err, &help)
+		require.ErrorIs(t, err, pflag.ErrHelp)
+		require.EqualError(t, err, "gonfig: help requested")
+		require.Equal(t, expectedUsage, help.Usage)
+	})
+
+	t.Run("custom output", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		config := config
+		config.Output = &buf
+
+		var help *gonfig.ErrHelp
+		require.ErrorAs(t, gonfig.New(config).Load(&TestLoaderConfig{}), &help)
+		require.Equal(t, expectedUsage, buf.String())
+	})
+
+	t.Run("exit on help", func(t *testing.T) {
+		var (
+			buf  bytes.Buffer
+			code = -1
+		)
+
+		config := config
+		config.Output = &buf
+		config.ExitOnHelp = true
+
+		require.NoError(t, gonfig.New(config, gonfig.WithCustomExit(func(c int) { code = c })).Load(&TestLoaderConfig{}))
+		require.Equal(t, 0, code)
+		require.Equal(t, expectedUsage, buf.String())
+	})
+
+	t.Run("parse errors", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		config := config
+		config.Args = []string{"--unknown"}
+		config.Output = &buf
+		require.EqualError(t, gonfig.New(config).Load(&TestLoaderConfig{}),
+			"gonfig: could not load: unknown flag: --unknown")
+		require.Empty(t, buf.String())
+	})
+}
+
+func TestLoadGeneric(t *testing.T) {
+	args := []string{"--string-field", "flag-value"}
+	envs := []string{"TEST_INT_VALUE=10"}
+
+	var expected TestLoaderConfig
+	require.NoError(t, gonfig.New(testLoaderOptions(args, envs)).Load(&expected))
+
+	cfg, err := gonfig.Load[TestLoaderConfig](testLoaderOptions(args, envs))
+	require.NoError(t, err)
+	require.Equal(t, expected, cfg)
+	require.Equal(t, expected, gonfig.MustLoad[TestLoaderConfig](testLoaderOptions(args, envs)))
+
+	_, err = gonfig.Load[int](gonfig.Config{})
+	require.EqualError(t, err, `gonfig: expect struct field, got "int"`)
+	require.ErrorIs(t, err, gonfig.ErrExpectStruct)
+
+	_, err = gonfig.Load[*TestLoaderConfig](gonfig.Config{})
+	require.ErrorIs(t, err, gonfig.ErrExpectStruct)
+
+	_, err = gonfig.Load[TestLoaderConfig](gonfig.Config{}, gonfig.WithOptions(nil))
+	require.EqualError(t, err, "gonfig: could not init option: invalid options type: <nil>")
+
+	_, err = gonfig.Load[struct {
+		Field string `required:"true"`
+	}](gonfig.Config{Envs: []string{}, Args: []string{}})
+	require.EqualError(t, err, "missing required fields:\n\t- field `Field` <string> is required")
+
+	require.Panics(t, func() { gonfig.MustLoad[int](gonfig.Config{}) })
+}
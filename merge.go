@@ -0,0 +1,99 @@
+package gonfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MergeTag defines the struct tag key used to specify how values of a field contributed by several
+// sources are merged. By default, the value of the source with the highest priority replaces others.
+//
+// Strategies are applied to values of built-in parsers, MapSource parsers and parallel parsers
+// (see WithParallelParsers). Custom parsers that decode values into the destination directly
+// are responsible for merging on their own. Values are merged from sources of the current load only,
+// so loading into the same destination twice does not accumulate them, and the value of the
+// "default" tag is used only when no other source contributes the field.
+//
+// Example usage:
+//
+//	AllowedCIDRs []string          `merge:"append"` // CIDRs from the file and from env are concatenated
+//	Labels       map[string]string `merge:"deep"`   // labels of all sources are merged by keys
+const MergeTag = "merge"
+
+// Merge strategies supported by the MergeTag.
+const (
+	// MergeReplace replaces the value of the field with the value of the next source, it is the default.
+	MergeReplace = "replace"
+
+	// MergeAppend appends elements of the slice contributed by the next source to the merged value.
+	MergeAppend = "append"
+
+	// MergeDeep merges keys of the map contributed by the next source into the merged value,
+	// values that are maps themselves are merged recursively, other values are replaced.
+	MergeDeep = "deep"
+)
+
+// mergeStrategy returns the merge strategy of the field and checks that it supports the type of the field.
+func mergeStrategy(field reflect.StructField) (string, error) {
+	strategy := field.Tag.Get(MergeTag)
+	switch {
+	case strategy == "" || strategy == MergeReplace:
+		return MergeReplace, nil
+	case strategy == MergeAppend && field.Type.Kind() == reflect.Slice,
+		strategy == MergeDeep && field.Type.Kind() == reflect.Map:
+		return strategy, nil
+	case strategy == MergeAppend || strategy == MergeDeep:
+		return "", fmt.Errorf("merge strategy %q of field %q is not supported by %s", strategy, field.Name, field.Type)
+	default:
+		return "", fmt.Errorf("unknown merge strategy %q of field %q", strategy, field.Name)
+	}
+}
+
+// mergeValue merges the value into the field with the provided strategy. The current value of
+// the field is never modified in place, so values of other sources are not affected.
+func mergeValue(field, value reflect.Value, strategy string) {
+	switch {
+	case strategy == MergeAppend && !field.IsNil():
+		out := reflect.MakeSlice(field.Type(), 0, field.Len()+value.Len())
+		field.Set(reflect.AppendSlice(reflect.AppendSlice(out, field), value))
+	case strategy == MergeDeep && !field.IsNil() && !value.IsNil():
+		field.Set(mergeMaps(field, value))
+	default:
+		field.Set(value)
+	}
+}
+
+// mergeMaps returns a new map with keys of both maps, keys of src override keys of dst,
+// except values that are maps in both of them, which are merged recursively.
+func mergeMaps(dst, src reflect.Value) reflect.Value {
+	out := reflect.MakeMapWithSize(dst.Type(), dst.Len()+src.Len())
+	for iter := dst.MapRange(); iter.Next(); {
+		out.SetMapIndex(iter.Key(), iter.Value())
+	}
+
+	for iter := src.MapRange(); iter.Next(); {
+		value := iter.Value()
+		if prev := out.MapIndex(iter.Key()); prev.IsValid() {
+			if a, b := mapOf(prev), mapOf(value); a.IsValid() && b.IsValid() && a.Type() == b.Type() {
+				value = mergeMaps(a, b)
+			}
+		}
+
+		out.SetMapIndex(iter.Key(), value)
+	}
+
+	return out
+}
+
+// mapOf returns the map held by the value (directly or in an interface), or an invalid value.
+func mapOf(value reflect.Value) reflect.Value {
+	if value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Map || value.IsNil() {
+		return reflect.Value{}
+	}
+
+	return value
+}
@@ -0,0 +1,126 @@
+package gonfig_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/im-kulikov/gonfig"
+)
+
+type MergeConfig struct {
+	AllowedCIDRs []string          `json:"allowed_cidrs" env:"ALLOWED_CIDRS" flag:"allowed-cidr" merge:"append" default:"127.0.0.1/32"`
+	Hosts        []string          `json:"hosts" env:"HOSTS" default:"localhost"`
+	Labels       map[string]string `json:"labels" env:"LABELS" merge:"deep"`
+	Plain        map[string]string `json:"plain" env:"PLAIN"`
+	Meta         map[string]any    `json:"meta" merge:"deep"`
+}
+
+func TestMergeStrategies(t *testing.T) {
+	file := &mapSource{name: "file", values: map[string]any{
+		"allowed_cidrs": []any{"10.0.0.0/8"},
+		"hosts":         []any{"file"},
+		"labels":        map[string]any{"team": "core", "env": "dev"},
+		"plain":         map[string]any{"team": "core"},
+		"meta": map[string]any{
+			"owner": map[string]any{"name": "core", "email": "core@example.com"},
+			"tier":  1,
+		},
+	}}
+
+	remote := &mapSource{name: "remote", values: map[string]any{
+		"meta": map[string]any{
+			"owner": map[string]any{"name": "platform"},
+		},
+	}}
+
+	var cfg MergeConfig
+	require.NoError(t, gonfig.New(gonfig.Config{
+		Envs: []string{"ALLOWED_CIDRS=192.168.0.0/16", "HOSTS=env", "LABELS_env=prod", "PLAIN_ENV=prod"},
+		Args: []string{"--allowed-cidr", "172.16.0.0/12"},
+	}, gonfig.WithMapSource(file), gonfig.WithMapSource(remote)).Load(&cfg))
+
+	require.Equal(t, []string{"192.168.0.0/16", "10.0.0.0/8", "172.16.0.0/12"}, cfg.AllowedCIDRs,
+		"the default is not merged with values of other sources")
+	require.Equal(t, []string{"file"}, cfg.Hosts)
+	require.Equal(t, map[string]string{"team": "core", "env": "dev"}, cfg.Labels)
+	require.Equal(t, map[string]string{"team": "core"}, cfg.Plain)
+	require.Equal(t, map[string]any{
+		"owner": map[string]any{"name": "platform", "email": "core@example.com"},
+		"tier":  1,
+	}, cfg.Meta)
+
+	t.Run("default as fallback", func(t *testing.T) {
+		var cfg MergeConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}}).Load(&cfg))
+		require.Equal(t, []string{"127.0.0.1/32"}, cfg.AllowedCIDRs)
+
+		cfg = MergeConfig{}
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{"ALLOWED_CIDRS=1.1.1.1/32"}, Args: []string{}}).Load(&cfg))
+		require.Equal(t, []string{"1.1.1.1/32"}, cfg.AllowedCIDRs)
+	})
+
+	t.Run("repeated load", func(t *testing.T) {
+		var cfg MergeConfig
+
+		loader := gonfig.New(gonfig.Config{
+			Envs: []string{"ALLOWED_CIDRS=1.1.1.1/32", "LABELS_env=prod"},
+			Args: []string{},
+		}, gonfig.WithMapSource(file))
+		for range 2 {
+			require.NoError(t, loader.Load(&cfg))
+			require.Equal(t, []string{"1.1.1.1/32", "10.0.0.0/8"}, cfg.AllowedCIDRs)
+			require.Equal(t, map[string]string{"team": "core", "env": "dev"}, cfg.Labels)
+		}
+	})
+
+	t.Run("deep merge keeps lower layers", func(t *testing.T) {
+		var cfg MergeConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{"LABELS_env=prod"}, Args: []string{}},
+			gonfig.WithMapSource(file),
+			gonfig.WithOrder(gonfig.ParserDefaults, "file", gonfig.ParserEnv, gonfig.ParserFlags)).Load(&cfg))
+
+		require.Equal(t, map[string]string{"team": "core", "env": "prod"}, cfg.Labels)
+	})
+
+	t.Run("parallel", func(t *testing.T) {
+		var cfg MergeConfig
+
+		loader := gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+			gonfig.WithCustomParser(gonfig.NewCustomParser("a", func(dest any) error {
+				dest.(*MergeConfig).AllowedCIDRs = []string{"10.0.0.0/8"}
+				dest.(*MergeConfig).Labels = map[string]string{"team": "core"}
+				return nil
+			})),
+			gonfig.WithCustomParser(gonfig.NewCustomParser("b", func(dest any) error {
+				dest.(*MergeConfig).AllowedCIDRs = []string{"192.168.0.0/16"}
+				dest.(*MergeConfig).Labels = map[string]string{"env": "prod"}
+				return nil
+			})),
+			gonfig.WithParallelParsers("a", "b"))
+		require.NoError(t, loader.Load(&cfg))
+
+		require.Equal(t, []string{"10.0.0.0/8", "192.168.0.0/16"}, cfg.AllowedCIDRs)
+		require.Equal(t, map[string]string{"team": "core", "env": "prod"}, cfg.Labels)
+
+		// values of the previous load are not merged again.
+		require.NoError(t, loader.Load(&cfg))
+		require.Equal(t, []string{"10.0.0.0/8", "192.168.0.0/16"}, cfg.AllowedCIDRs)
+	})
+}
+
+func TestMergeStrategies_Errors(t *testing.T) {
+	var unknown struct {
+		Hosts []string `merge:"union"`
+	}
+
+	require.EqualError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}}).Load(&unknown),
+		`gonfig: could not load: unknown merge strategy "union" of field "Hosts"`)
+
+	var unsupported struct {
+		Hosts map[string]string `merge:"append"`
+	}
+
+	require.EqualError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}}).Load(&unsupported),
+		`gonfig: could not load: merge strategy "append" of field "Hosts" is not supported by map[string]string`)
+}
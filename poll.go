@@ -0,0 +1,104 @@
+package gonfig
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Default values used by Poll when PollOptions fields are not set.
+const (
+	DefaultPollInterval   = 30 * time.Second // DefaultPollInterval is the interval between successful reloads.
+	DefaultPollMaxBackoff = 5 * time.Minute  // DefaultPollMaxBackoff is the upper bound of the delay after failures.
+)
+
+// PollOptions holds the configuration of the polling scheduler.
+//
+// Fields:
+//
+//   - Interval: The delay between successful reloads. Defaults to DefaultPollInterval.
+//
+//   - MaxBackoff: The upper bound of the delay between reloads after failures. Every consecutive
+//     failure doubles the delay, starting from Interval. Defaults to DefaultPollMaxBackoff.
+//
+//   - Jitter: A fraction of the delay (from 0 to 1) that is randomly added to or subtracted from it,
+//     so that many instances do not hit a remote source at the same moment.
+//
+//   - OnError: An optional callback that is called with every reload error.
+type PollOptions struct {
+	Interval   time.Duration
+	MaxBackoff time.Duration
+	Jitter     float64
+	OnError    func(error)
+}
+
+// Poll periodically calls reload until the context is cancelled. It is a polling scheduler for
+// sources that do not support native watches (e.g. HTTP or Consul): the first reload happens
+// after the first interval, successful reloads are repeated every Interval, and failed ones
+// are retried with exponential backoff (up to MaxBackoff) and jitter.
+//
+// Poll blocks until the context is cancelled and returns the context error.
+//
+// Example usage:
+//
+//	go gonfig.Poll(ctx, gonfig.PollOptions{Interval: time.Minute}, value.Reload)
+func Poll(ctx context.Context, options PollOptions, reload func() error) error {
+	if options.Interval <= 0 {
+		options.Interval = DefaultPollInterval
+	}
+
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = DefaultPollMaxBackoff
+	}
+
+	timer := time.NewTimer(options.delay(0))
+	defer timer.Stop()
+
+	for failures := 0; ; {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if err := reload(); err != nil {
+			failures++
+
+			if options.OnError != nil {
+				options.OnError(err)
+			}
+		} else {
+			failures = 0
+		}
+
+		timer.Reset(options.delay(failures))
+	}
+}
+
+// Poll periodically reloads the Value using its parser until the context is cancelled,
+// so that polled changes go through the same pipeline as Reload: immutable fields are
+// checked and subscribers are notified. The context is passed to every ReloadContext call.
+// See the package-level Poll for details.
+func (v *Value[T]) Poll(ctx context.Context, options PollOptions) error {
+	return Poll(ctx, options, func() error { return v.ReloadContext(ctx) })
+}
+
+// delay calculates the delay before the next reload based on the number of consecutive failures.
+// The delay is doubled for every failure, capped by MaxBackoff and randomized by Jitter.
+func (o PollOptions) delay(failures int) time.Duration {
+	delay := o.Interval
+	for range failures {
+		if delay *= 2; delay >= o.MaxBackoff {
+			delay = o.MaxBackoff
+
+			break
+		}
+	}
+
+	if o.Jitter > 0 {
+		jitter := min(o.Jitter, 1) * float64(delay)
+		delay += time.Duration(jitter * (2*rand.Float64() - 1))
+	}
+
+	return delay
+}
@@ -0,0 +1,91 @@
+package gonfig_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/im-kulikov/gonfig"
+)
+
+func TestPoll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		calls  atomic.Int32
+		errs   atomic.Int32
+		failed = errors.New("source is unavailable")
+	)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- gonfig.Poll(ctx, gonfig.PollOptions{
+			Interval:   time.Millisecond,
+			MaxBackoff: 4 * time.Millisecond,
+			Jitter:     0.5,
+			OnError: func(err error) {
+				assert.ErrorIs(t, err, failed) // require can not stop the test from the polling goroutine
+				errs.Add(1)
+			},
+		}, func() error {
+			// fail every other call to exercise the backoff
+			if calls.Add(1)%2 == 0 {
+				return failed
+			}
+
+			return nil
+		})
+	}()
+
+	require.Eventually(t, func() bool { return calls.Load() >= 6 }, time.Second, time.Millisecond)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	require.GreaterOrEqual(t, errs.Load(), int32(3))
+}
+
+func TestValue_Poll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var count atomic.Int64
+	value := gonfig.NewValue[ValueConfig](gonfig.NewCustomParser("remote", func(dest any) error {
+		dest.(*ValueConfig).Count = int(count.Add(1))
+
+		return nil
+	}))
+
+	changes := make(chan gonfig.Change, 16)
+	defer value.SubscribePath("Count", func(change gonfig.Change) {
+		select {
+		case changes <- change:
+		default:
+		}
+	})()
+
+	done := make(chan error, 1)
+	go func() { done <- value.Poll(ctx, gonfig.PollOptions{Interval: time.Millisecond}) }()
+
+	require.Equal(t, gonfig.Change{Path: "Count", Old: 0, New: 1}, <-changes)
+	require.Equal(t, gonfig.Change{Path: "Count", Old: 1, New: 2}, <-changes)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestPoll_Defaults(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.ErrorIs(t, gonfig.Poll(ctx, gonfig.PollOptions{}, func() error {
+		t.Fatal("reload should not be called before the first interval")
+
+		return nil
+	}), context.Canceled)
+}
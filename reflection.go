@@ -17,6 +17,27 @@ type ReflectValue struct {
 	Owner *ReflectValue       // Pointer to the owner (parent) ReflectValue, if applicable.
 }
 
+// Path returns the full path to the field in the nested structure, built from the names
+// of the field and all its owners and joined by dots (e.g. "Database.MaxConns").
+func (v *ReflectValue) Path() string {
+	var path string
+	for owner := v; owner != nil; owner = owner.Owner {
+		if owner.Field.Name == "" {
+			continue
+		}
+
+		if path == "" {
+			path = owner.Field.Name
+
+			continue
+		}
+
+		path = owner.Field.Name + "." + path
+	}
+
+	return path
+}
+
 // ReflectOptions defines options for reflecting on fields of a struct.
 // These options specify conditions that determine which fields to include in the reflection process.
 type ReflectOptions struct {
@@ -185,7 +206,7 @@ func (o *ReflectOptions) IsValid(v reflect.Value) bool {
 //	options := ReflectOptions{AsField: []reflect.Type{reflect.TypeOf(int(0))}}
 //	isField := options.IsField(reflect.ValueOf(someField))  // returns true/false based on field type.
 func (o *ReflectOptions) IsField(v reflect.Value) bool {
-	if slices.Contains(o.AsField, v.Type()) {
+	if slices.Contains(o.AsField, v.Type()) || isSecretType(v.Type()) {
 		return true
 	}
 
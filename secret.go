@@ -0,0 +1,127 @@
+package gonfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+
+	"github.com/spf13/pflag"
+)
+
+// Secret holds a sensitive value of a configuration field, e.g. a password or a token.
+// Unlike the SecretTag, the wrapper also protects the value outside the loader: it is redacted
+// when the field (or the whole configuration) is printed with fmt, encoded to JSON or logged with slog.
+// The value itself is only available through Value.
+//
+// Fields of the Secret type are loaded like fields of the wrapped type, and are treated as
+// tagged with `secret:"true"` by the loader (see SecretTag).
+//
+// Example usage:
+//
+//	type Config struct {
+//	    Password gonfig.Secret[string] `env:"PASSWORD" flag:"password"`
+//	}
+//
+//	db.Connect(cfg.Password.Value())
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret wraps the provided value into a Secret.
+func NewSecret[T any](value T) Secret[T] { return Secret[T]{value: value} }
+
+// Value returns the wrapped value.
+func (s Secret[T]) Value() T { return s.value }
+
+// String returns RedactedValue, so the value is not exposed by fmt.
+func (s Secret[T]) String() string { return RedactedValue }
+
+// Format writes RedactedValue for any verb, so the value is not exposed even with %#v or %d.
+func (s Secret[T]) Format(f fmt.State, _ rune) { _, _ = io.WriteString(f, RedactedValue) }
+
+// MarshalJSON encodes RedactedValue as a JSON string, so the value is not exposed in JSON dumps.
+func (s Secret[T]) MarshalJSON() ([]byte, error) { return json.Marshal(RedactedValue) }
+
+// LogValue implements slog.LogValuer, so the value is not exposed in logs.
+func (s Secret[T]) LogValue() slog.Value { return slog.StringValue(RedactedValue) }
+
+// UnmarshalJSON decodes the JSON value into the wrapped value, so files exported with
+// ExportWithSecrets can be decoded back.
+func (s *Secret[T]) UnmarshalJSON(data []byte) error { return json.Unmarshal(data, &s.value) }
+
+// UnmarshalText decodes the text into the wrapped value with the same rules as environment
+// variables, e.g. for YAML or TOML decoders.
+func (s *Secret[T]) UnmarshalText(text []byte) error {
+	return decodeValue(reflect.ValueOf(&s.value).Elem(), string(text), "")
+}
+
+// secretTarget returns the pointer to the wrapped value, so loaders decode values into it.
+func (s *Secret[T]) secretTarget() any { return &s.value }
+
+// secretValue is implemented by pointers to Secret.
+type secretValue interface {
+	secretTarget() any
+}
+
+// secretValueType is the reflect.Type of the secretValue interface.
+var secretValueType = reflect.TypeFor[secretValue]()
+
+// isSecretType reports whether the type is a Secret.
+func isSecretType(typ reflect.Type) bool { return reflect.PointerTo(typ).Implements(secretValueType) }
+
+// secretOf returns the wrapped value of the Secret field, or the field itself for other types.
+func secretOf(field reflect.Value) reflect.Value {
+	if !field.CanAddr() || !isSecretType(field.Type()) {
+		return field
+	}
+
+	return reflect.ValueOf(field.Addr().Interface().(secretValue).secretTarget()).Elem()
+}
+
+// isSecret reports whether the value of the field must be redacted: the field or any of its
+// owners is tagged with `secret:"true"`, or the field is a Secret.
+func isSecret(elem *ReflectValue) bool {
+	return hasOwnerTag(elem, SecretTag, "true") || isSecretType(elem.Value.Type())
+}
+
+// redactError hides the error of the secret field, because errors of parsers and decoders
+// often echo the input, e.g. `parsing "p@ssw0rd": invalid syntax`.
+func redactError(elem *ReflectValue, err error) error {
+	if err == nil || !isSecret(elem) {
+		return err
+	}
+
+	return fmt.Errorf("invalid value of type %s (%s)", secretOf(elem.Value).Type(), RedactedValue)
+}
+
+// secretFlag wraps the flag of the secret field, so pflag does not echo the invalid input in
+// its error: the error is kept and reported by the flags parser instead, see flagsParser.
+type secretFlag struct {
+	pflag.Value
+
+	err error
+}
+
+// Set sets the value of the flag and keeps the error, if any.
+func (f *secretFlag) Set(value string) error {
+	if err := f.Value.Set(value); err != nil {
+		f.err = err
+	}
+
+	return nil
+}
+
+// secretFlagError returns the error of the first secret flag that got an invalid value.
+func secretFlagError(set *pflag.FlagSet) error {
+	var err error
+	set.Visit(func(flag *pflag.Flag) {
+		if secret, ok := flag.Value.(*secretFlag); ok && secret.err != nil && err == nil {
+			err = fmt.Errorf("invalid argument for %q flag: invalid value of type %s (%s)",
+				"--"+flag.Name, secret.Value.Type(), RedactedValue)
+		}
+	})
+
+	return err
+}
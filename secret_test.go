@@ -0,0 +1,136 @@
+package gonfig_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+
+	"github.com/im-kulikov/gonfig"
+)
+
+type SecretConfig struct {
+	Password gonfig.Secret[string] `json:"password" env:"PASSWORD" flag:"password" default:"changeme" usage:"database password"`
+	Port     gonfig.Secret[int]    `json:"port" env:"PORT" flag:"port"`
+	Token    string                `json:"token" env:"TOKEN" flag:"token" default:"default-token" secret:"true"`
+	User     string                `json:"user" env:"USER" default:"admin"`
+}
+
+func TestSecret(t *testing.T) {
+	secret := gonfig.NewSecret("p@ssw0rd")
+	require.Equal(t, "p@ssw0rd", secret.Value())
+
+	require.Equal(t, gonfig.RedactedValue, secret.String())
+	require.Equal(t, gonfig.RedactedValue, fmt.Sprintf("%v", secret))
+	require.Equal(t, gonfig.RedactedValue, fmt.Sprintf("%#v", secret))
+	require.Equal(t, gonfig.RedactedValue, fmt.Sprintf("%d", gonfig.NewSecret(42)))
+	require.Equal(t, "{S:"+gonfig.RedactedValue+"}", fmt.Sprintf("%+v", struct{ S gonfig.Secret[string] }{secret}))
+
+	data, err := json.Marshal(struct{ Password gonfig.Secret[string] }{secret})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Password": "******"}`, string(data))
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("loaded", "password", secret)
+	require.Contains(t, buf.String(), "password="+gonfig.RedactedValue)
+	require.NotContains(t, buf.String(), "p@ssw0rd")
+}
+
+func TestSecret_Load(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		var cfg SecretConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}}).Load(&cfg))
+		require.Equal(t, "changeme", cfg.Password.Value())
+		require.Equal(t, "default-token", cfg.Token)
+	})
+
+	t.Run("envs", func(t *testing.T) {
+		var cfg SecretConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{"PASSWORD=env", "PORT=5432"}, Args: []string{}}).Load(&cfg))
+		require.Equal(t, "env", cfg.Password.Value())
+		require.Equal(t, 5432, cfg.Port.Value())
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		var cfg SecretConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{"--password", "flag", "--port", "6432"}}).Load(&cfg))
+		require.Equal(t, "flag", cfg.Password.Value())
+		require.Equal(t, 6432, cfg.Port.Value())
+	})
+
+	t.Run("map source", func(t *testing.T) {
+		var cfg SecretConfig
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}},
+			gonfig.WithMapSource(&mapSource{name: "file", values: map[string]any{"password": "file", "port": 7432}})).Load(&cfg))
+		require.Equal(t, "file", cfg.Password.Value())
+		require.Equal(t, 7432, cfg.Port.Value())
+	})
+}
+
+func TestSecret_Redaction(t *testing.T) {
+	t.Run("usage", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{"--help"}, Output: &buf}).Load(&SecretConfig{})
+		require.ErrorIs(t, err, pflag.ErrHelp)
+
+		require.NotContains(t, buf.String(), "changeme")
+		require.NotContains(t, buf.String(), "default-token")
+		require.Contains(t, buf.String(), `--password string   database password (default "******")`)
+		require.Contains(t, buf.String(), "'PASSWORD' <string> — database password (default: ******)")
+		require.Contains(t, buf.String(), "'TOKEN' <string> (default: ******)")
+		require.Contains(t, buf.String(), "'USER' <string> (default: admin)")
+	})
+
+	t.Run("decode errors", func(t *testing.T) {
+		err := gonfig.New(gonfig.Config{Envs: []string{"PORT=p@ssw0rd"}, Args: []string{}}).Load(&SecretConfig{})
+		require.EqualError(t, err, `gonfig: could not load: (env) could not decode field "Port": invalid value of type int (******)`)
+
+		err = gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{"--port", "p@ssw0rd"}}).Load(&SecretConfig{})
+		require.EqualError(t, err, `gonfig: could not load: invalid argument for "--port" flag: invalid value of type int (******)`)
+
+		type Broken struct {
+			Port int `default:"p@ssw0rd" secret:"true"`
+		}
+
+		err = gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}}).Load(&Broken{})
+		require.EqualError(t, err, `gonfig: could not load: (defaults) failed to set field "Port": invalid value of type int (******)`)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		require.Equal(t, []gonfig.Change{
+			{Path: "Password", Old: gonfig.RedactedValue, New: gonfig.RedactedValue},
+		}, gonfig.Diff(&SecretConfig{}, &SecretConfig{Password: gonfig.NewSecret("new")}))
+	})
+
+	t.Run("explain", func(t *testing.T) {
+		var cfg SecretConfig
+		loader := gonfig.New(gonfig.Config{Envs: []string{"PASSWORD=env"}, Args: []string{"--token", "flag"}})
+		sources, err := loader.LoadExplain(context.Background(), &cfg)
+		require.NoError(t, err)
+		require.Equal(t, []gonfig.FieldSource{
+			{Path: "Password", Source: gonfig.ParserEnv, Key: "PASSWORD", Raw: gonfig.RedactedValue},
+			{Path: "Token", Source: gonfig.ParserFlags, Key: "--token", Raw: gonfig.RedactedValue},
+			{Path: "User", Source: gonfig.ParserDefaults, Raw: "admin"},
+		}, sources)
+	})
+
+	t.Run("print config", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, gonfig.New(gonfig.Config{Envs: []string{"PASSWORD=env"}, Args: []string{"--print-config=env"}, Output: &buf},
+			gonfig.WithConfigCommands(),
+			gonfig.WithCustomExit(func(int) {})).Load(&SecretConfig{}))
+		require.Equal(t, `# env PASSWORD
+PASSWORD=******
+PORT=******
+# defaults
+TOKEN=******
+# defaults
+USER=admin
+`, buf.String())
+	})
+}
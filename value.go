@@ -0,0 +1,209 @@
+package gonfig
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrRestartRequired is returned by Value.Reload when the reloaded configuration changes
+// fields tagged with `reload:"false"`. Such a configuration is not applied, the current one
+// stays in place until the application is restarted.
+type ErrRestartRequired struct {
+	Changes []Change // Changes of the immutable fields.
+}
+
+// Error formats the ErrRestartRequired into a descriptive error message.
+func (e ErrRestartRequired) Error() string {
+	paths := make([]string, 0, len(e.Changes))
+	for _, change := range e.Changes {
+		paths = append(paths, "`"+change.Path+"`")
+	}
+
+	return fmt.Sprintf("fields %s require restart to be changed", strings.Join(paths, ", "))
+}
+
+// Value is an atomically replaceable holder for a loaded configuration of type T.
+// It is designed for configurations that are reloaded at runtime and read by many goroutines.
+//
+// Readers call Load and always observe a fully loaded configuration: a reload decodes into a
+// fresh instance of T and only publishes it (with a single atomic pointer swap) after the
+// parser has finished successfully. The previously loaded instance is never mutated.
+//
+// Example usage:
+//
+//	value := gonfig.NewValue[Config](gonfig.New(gonfig.Config{}))
+//	if err := value.Reload(); err != nil {
+//	    panic(err)
+//	}
+//
+//	cancel := value.Subscribe(func(old, new *Config) { /* react on changes */ })
+//	defer cancel()
+//
+//	cfg := value.Load() // safe to call from any goroutine
+type Value[T any] struct {
+	ptr    atomic.Pointer[T]
+	parser Parser
+
+	write   sync.Mutex    // serializes Store and Reload, so subscribers observe changes in order.
+	sources []FieldSource // sources of the current configuration, guarded by write.
+
+	mu   sync.RWMutex // guards subs and next.
+	next int
+	subs map[int]func(old, new *T)
+}
+
+// NewValue creates a new Value that uses the provided parser to reload the configuration.
+// The parser can be nil, in which case the Value can only be updated through Store.
+// The returned Value holds no configuration until the first Reload or Store.
+func NewValue[T any](parser Parser) *Value[T] {
+	return &Value[T]{parser: parser, subs: make(map[int]func(old, new *T))}
+}
+
+// Load returns the current configuration or nil if nothing was loaded yet.
+// The returned value must be treated as read-only, because it is shared between all readers.
+func (v *Value[T]) Load() *T { return v.ptr.Load() }
+
+// Store atomically replaces the current configuration with the provided one
+// and notifies all subscribers about the change.
+func (v *Value[T]) Store(val *T) {
+	v.write.Lock()
+	defer v.write.Unlock()
+
+	v.sources = nil
+	v.notify(v.ptr.Swap(val), val)
+}
+
+// Reload loads a fresh configuration using the parser provided to NewValue and publishes it
+// through Store. If the parser fails, the current configuration remains untouched and the error
+// is returned.
+//
+// Once a configuration is loaded, fields tagged with `reload:"false"` can not be changed by
+// Reload: if the fresh configuration has different values for them, it is rejected with
+// ErrRestartRequired and the current configuration remains untouched.
+func (v *Value[T]) Reload() error { return v.ReloadContext(context.Background()) }
+
+// ReloadContext works like Reload, but passes the context to the parser if it implements
+// the ContextParser interface (e.g. a loader created by New).
+func (v *Value[T]) ReloadContext(ctx context.Context) error {
+	if v.parser == nil {
+		return fmt.Errorf("gonfig: could not reload: parser is not defined")
+	}
+
+	v.write.Lock()
+	defer v.write.Unlock()
+
+	val := new(T)
+	sources, err := v.load(ctx, val)
+	if err != nil {
+		return fmt.Errorf("gonfig: could not reload: %w", err)
+	}
+
+	if old := v.ptr.Load(); old != nil {
+		var immutable []Change
+		for _, change := range explainChanges(Diff(old, val), sources) {
+			if change.RequiresRestart {
+				immutable = append(immutable, change)
+			}
+		}
+
+		if len(immutable) > 0 {
+			return fmt.Errorf("gonfig: could not reload: %w", ErrRestartRequired{Changes: immutable})
+		}
+	}
+
+	v.sources = sources
+	v.notify(v.ptr.Swap(val), val)
+
+	return nil
+}
+
+// Subscribe registers a callback that is called after each Store or Reload with the previous
+// and the new configuration. Callbacks are called synchronously and in order of changes, so
+// they must not call Store or Reload themselves. The returned function removes the subscription.
+func (v *Value[T]) Subscribe(fn func(old, new *T)) (cancel func()) {
+	if fn == nil {
+		return func() {}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	id := v.next
+	v.next++
+	v.subs[id] = fn
+
+	return func() {
+		v.mu.Lock()
+		defer v.mu.Unlock()
+
+		delete(v.subs, id)
+	}
+}
+
+// SubscribePath registers a callback that is called for each change of the field with the
+// provided path (or of any field nested into it) after Store or Reload. Paths are built from
+// the Go field names joined by dots, e.g. "Database.MaxConns", see Diff for details. The Source
+// of the change is set when the configuration was reloaded with a Loader, see Loader.LoadExplain.
+// The returned function removes the subscription.
+func (v *Value[T]) SubscribePath(path string, fn func(Change)) (cancel func()) {
+	if fn == nil {
+		return func() {}
+	}
+
+	return v.Subscribe(func(old, new *T) {
+		for _, change := range explainChanges(Diff(old, new), v.sources) {
+			if change.Matches(path) {
+				fn(change)
+			}
+		}
+	})
+}
+
+// load loads the configuration into the value with the parser. Sources of fields are returned
+// when the parser knows them, i.e. it is a Loader, see Loader.LoadExplain.
+func (v *Value[T]) load(ctx context.Context, val *T) ([]FieldSource, error) {
+	if loader, ok := v.parser.(Loader); ok {
+		return loader.LoadExplain(ctx, val)
+	}
+
+	return nil, loadContext(ctx, v.parser, val)
+}
+
+// explainChanges sets sources of the changes, if they are known.
+func explainChanges(changes []Change, sources []FieldSource) []Change {
+	if len(sources) == 0 {
+		return changes
+	}
+
+	types := make(map[string]ParserType, len(sources))
+	for _, source := range sources {
+		types[source.Path] = source.Source
+	}
+
+	for i := range changes {
+		changes[i].Source = types[changes[i].Path]
+	}
+
+	return changes
+}
+
+// notify calls all registered subscribers with the previous and the new configuration.
+// Subscribers are copied under the read lock (in order of subscription), so they can subscribe
+// or cancel from the callback.
+func (v *Value[T]) notify(old, val *T) {
+	v.mu.RLock()
+	subs := make([]func(old, new *T), 0, len(v.subs))
+	for _, id := range slices.Sorted(maps.Keys(v.subs)) {
+		subs = append(subs, v.subs[id])
+	}
+	v.mu.RUnlock()
+
+	for _, fn := range subs {
+		fn(old, val)
+	}
+}
@@ -0,0 +1,160 @@
+package gonfig_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/im-kulikov/gonfig"
+)
+
+type ValueConfig struct {
+	Name  string `env:"NAME" default:"default-name"`
+	Count int    `env:"COUNT" default:"1"`
+}
+
+func TestValue(t *testing.T) {
+	envs := []string{"NAME=first"}
+	value := gonfig.NewValue[ValueConfig](gonfig.New(gonfig.Config{SkipFlags: true},
+		gonfig.WithCustomParser(gonfig.NewCustomParser("dynamic", func(dest any) error {
+			return gonfig.LoadEnvs(gonfig.PrepareEnvs(envs, ""), dest)
+		}))))
+
+	require.Nil(t, value.Load())
+
+	var calls [][2]*ValueConfig
+	cancel := value.Subscribe(func(old, new *ValueConfig) {
+		calls = append(calls, [2]*ValueConfig{old, new})
+	})
+
+	require.NoError(t, value.Reload())
+	first := value.Load()
+	require.Equal(t, &ValueConfig{Name: "first", Count: 1}, first)
+
+	envs = []string{"NAME=second", "COUNT=2"}
+	require.NoError(t, value.Reload())
+	second := value.Load()
+	require.Equal(t, &ValueConfig{Name: "second", Count: 2}, second)
+	require.Equal(t, &ValueConfig{Name: "first", Count: 1}, first, "previous value should not be mutated")
+
+	require.Equal(t, [][2]*ValueConfig{{nil, first}, {first, second}}, calls)
+
+	cancel()
+	value.Store(&ValueConfig{Name: "stored"})
+	require.Len(t, calls, 2, "cancelled subscriber should not be called")
+	require.Equal(t, "stored", value.Load().Name)
+}
+
+func TestValue_SubscribeOrder(t *testing.T) {
+	value := gonfig.NewValue[ValueConfig](nil)
+
+	// cancelled subscriptions do not leave anything behind, e.g. per-request subscriptions.
+	for range 1000 {
+		value.Subscribe(func(_, _ *ValueConfig) {})()
+	}
+
+	var calls []int
+	for i := range 3 {
+		value.Subscribe(func(_, _ *ValueConfig) { calls = append(calls, i) })
+	}
+
+	value.Store(&ValueConfig{Name: "stored"})
+	require.Equal(t, []int{0, 1, 2}, calls, "subscribers are called in order of subscription")
+}
+
+func TestValue_Errors(t *testing.T) {
+	require.EqualError(t, gonfig.NewValue[ValueConfig](nil).Reload(),
+		"gonfig: could not reload: parser is not defined")
+
+	value := gonfig.NewValue[ValueConfig](gonfig.NewCustomParser("broken", func(any) error {
+		return errors.New("broken")
+	}))
+
+	value.Store(&ValueConfig{Name: "stored"})
+	require.EqualError(t, value.Reload(), "gonfig: could not reload: broken")
+	require.Equal(t, "stored", value.Load().Name, "failed reload should keep current value")
+
+	require.NotPanics(t, value.Subscribe(nil))
+}
+
+func TestValue_Concurrent(t *testing.T) {
+	value := gonfig.NewValue[ValueConfig](gonfig.New(gonfig.Config{Envs: []string{}, Args: []string{}}))
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			require.NoError(t, value.Reload())
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			if cfg := value.Load(); cfg != nil {
+				require.Equal(t, "default-name", cfg.Name)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+type ImmutableConfig struct {
+	Listen  string `env:"LISTEN" reload:"false"`
+	Level   string `env:"LEVEL"`
+	Storage struct {
+		Path string `env:"PATH"`
+	} `env:"STORAGE" reload:"false"`
+}
+
+func TestValue_Immutable(t *testing.T) {
+	envs := []string{"LISTEN=:8080", "LEVEL=info", "STORAGE_PATH=/data"}
+	value := gonfig.NewValue[ImmutableConfig](gonfig.NewCustomParser("envs", func(dest any) error {
+		return gonfig.LoadEnvs(gonfig.PrepareEnvs(envs, ""), dest)
+	}))
+
+	require.NoError(t, value.Reload())
+
+	envs = []string{"LISTEN=:8080", "LEVEL=debug", "STORAGE_PATH=/data"}
+	require.NoError(t, value.Reload(), "mutable fields can be changed")
+	require.Equal(t, "debug", value.Load().Level)
+
+	envs = []string{"LISTEN=:9090", "LEVEL=warn", "STORAGE_PATH=/tmp"}
+	err := value.Reload()
+	require.EqualError(t, err,
+		"gonfig: could not reload: fields `Listen`, `Storage.Path` require restart to be changed")
+
+	var restart gonfig.ErrRestartRequired
+	require.ErrorAs(t, err, &restart)
+	require.Equal(t, []gonfig.Change{
+		{Path: "Listen", Old: ":8080", New: ":9090", RequiresRestart: true},
+		{Path: "Storage.Path", Old: "/data", New: "/tmp", RequiresRestart: true},
+	}, restart.Changes)
+
+	require.Equal(t, "debug", value.Load().Level, "rejected reload should keep current value")
+	require.Equal(t, ":8080", value.Load().Listen)
+
+	value.Store(&ImmutableConfig{Listen: ":9090"})
+	require.Equal(t, ":9090", value.Load().Listen, "store is not restricted by immutable fields")
+}
+
+func TestValue_ReloadContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	value := gonfig.NewValue[ValueConfig](gonfig.NewCustomContextParser("remote", func(ctx context.Context, _ any) error {
+		return ctx.Err()
+	}))
+
+	require.ErrorIs(t, value.ReloadContext(ctx), context.Canceled)
+	require.Nil(t, value.Load())
+
+	require.NoError(t, value.ReloadContext(context.Background()))
+	require.NotNil(t, value.Load())
+}